@@ -0,0 +1,215 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Errors of the account linking
+var (
+	ErrAccessTokenMissing = errors.New("access token missing")
+	ErrAccessTokenExpired = errors.New("access token expired")
+	ErrAccessTokenInvalid = errors.New("access token invalid")
+)
+
+// LinkedUser type is the user of the application an access token belongs to
+type LinkedUser struct {
+	ID string
+	// ExpiresAt is the expiry of the access token, or zero when unknown
+	ExpiresAt time.Time
+}
+
+// TokenResolver interface resolves access tokens of linked accounts. It
+// returns an error wrapping ErrAccessTokenExpired or ErrAccessTokenInvalid
+// for tokens which cannot be used. A nil user without an error is taken as an
+// invalid token.
+type TokenResolver interface {
+	ResolveToken(ctx context.Context, accessToken string) (*LinkedUser, error)
+}
+
+// ResolveLinkedUser function resolves accessToken with resolver, returning an
+// error wrapping ErrAccessTokenInvalid when the resolver returns neither a
+// user nor an error.
+func ResolveLinkedUser(ctx context.Context, resolver TokenResolver, accessToken string) (*LinkedUser, error) {
+	user, err := resolver.ResolveToken(ctx, accessToken)
+	if err == nil && user == nil {
+		return nil, fmt.Errorf("%w: no user", ErrAccessTokenInvalid)
+	}
+	return user, err
+}
+
+// TokenResolverFunc type
+type TokenResolverFunc func(ctx context.Context, accessToken string) (*LinkedUser, error)
+
+// ResolveToken method for implementing TokenResolver interface
+func (f TokenResolverFunc) ResolveToken(ctx context.Context, accessToken string) (*LinkedUser, error) {
+	return f(ctx, accessToken)
+}
+
+// CachingTokenResolver type caches the users resolved by a TokenResolver for
+// a TTL, or until the token expires, and the invalid tokens for the TTL.
+// Expired tokens and other errors are not cached.
+type CachingTokenResolver struct {
+	resolver TokenResolver
+	ttl      time.Duration
+	mu       sync.Mutex
+	cache    *ttlCache
+}
+
+// NewCachingTokenResolver function returns a resolver holding at most
+// capacity tokens
+func NewCachingTokenResolver(resolver TokenResolver, ttl time.Duration, capacity int) *CachingTokenResolver {
+	return &CachingTokenResolver{
+		resolver: resolver,
+		ttl:      ttl,
+		cache:    newTTLCache(capacity),
+	}
+}
+
+// ResolveToken method for implementing TokenResolver interface
+func (r *CachingTokenResolver) ResolveToken(ctx context.Context, accessToken string) (*LinkedUser, error) {
+	// tokens are hashed so that the cache does not hold credentials
+	sum := sha256.Sum256([]byte(accessToken))
+	key := hex.EncodeToString(sum[:])
+	r.mu.Lock()
+	cached, ok := r.cache.get(key)
+	r.mu.Unlock()
+	if ok {
+		if user, ok := cached.(*LinkedUser); ok {
+			return user, nil
+		}
+		return nil, cached.(error)
+	}
+
+	user, err := ResolveLinkedUser(ctx, r.resolver, accessToken)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expiry := r.cache.now().Add(r.ttl)
+	switch {
+	case err == nil:
+		if !user.ExpiresAt.IsZero() && user.ExpiresAt.Before(expiry) {
+			expiry = user.ExpiresAt
+		}
+		r.cache.add(key, user, expiry)
+	case errors.Is(err, ErrAccessTokenInvalid) && !errors.Is(err, ErrAccessTokenExpired):
+		r.cache.add(key, err, expiry)
+	}
+	return user, err
+}
+
+type linkedUserContextKey struct{}
+
+// LinkedUserFromContext function returns the user resolved by
+// AccountLinkingHandler, or nil
+func LinkedUserFromContext(ctx context.Context) *LinkedUser {
+	user, _ := ctx.Value(linkedUserContextKey{}).(*LinkedUser)
+	return user
+}
+
+// AccessToken method returns the access token of the user of the request, or
+// an empty string
+func (m *RequestMessage) AccessToken() string {
+	if m.Context != nil && m.Context.System != nil && m.Context.System.User != nil && m.Context.System.User.AccessToken != "" {
+		return m.Context.System.User.AccessToken
+	}
+	if m.Session != nil && m.Session.User != nil {
+		return m.Session.User.AccessToken
+	}
+	return ""
+}
+
+// AccountLinkingHandler type requires a linked account for the selected
+// intents, or for all requests when no intent is selected. Requests with a
+// resolved access token are passed to Next with the user in the context, see
+// LinkedUserFromContext. Requests without an access token or with an invalid
+// one get LinkResponse, and requests with an expired one get ExpiredResponse.
+type AccountLinkingHandler struct {
+	Resolver        TokenResolver
+	Next            Handler
+	Intents         map[string]bool
+	LinkResponse    *ResponseMessage
+	ExpiredResponse *ResponseMessage
+}
+
+// NewAccountLinkingHandler function returns a handler with the default
+// responses asking the user to link the account in the Clova app
+func NewAccountLinkingHandler(resolver TokenResolver, next Handler, intents ...string) *AccountLinkingHandler {
+	h := &AccountLinkingHandler{
+		Resolver:        resolver,
+		Next:            next,
+		LinkResponse:    accountLinkingResponse("このスキルを使うには、Clovaアプリでアカウントを連携してください。"),
+		ExpiredResponse: accountLinkingResponse("アカウント連携の有効期限が切れました。Clovaアプリでもう一度アカウントを連携してください。"),
+	}
+	if len(intents) > 0 {
+		h.Intents = map[string]bool{}
+		for _, intent := range intents {
+			h.Intents[intent] = true
+		}
+	}
+	return h
+}
+
+func accountLinkingResponse(text string) *ResponseMessage {
+	return NewResponseBuilder().
+		OutputSpeech(NewOutputSpeechBuilder().AddSpeechText(text, SpeechInfoLangJA).Build()).
+		ShouldEndSession(true).
+		Build()
+}
+
+// ServeCEK method for implementing Handler interface
+func (h *AccountLinkingHandler) ServeCEK(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	if !h.requiresLink(message) {
+		return h.next(ctx, message)
+	}
+	accessToken := message.AccessToken()
+	if accessToken == "" {
+		LoggerFromContext(ctx).Info("account linking required", "reason", ErrAccessTokenMissing.Error())
+		return h.LinkResponse, nil
+	}
+	user, err := ResolveLinkedUser(ctx, h.Resolver, accessToken)
+	switch {
+	case errors.Is(err, ErrAccessTokenExpired):
+		LoggerFromContext(ctx).Info("account linking required", "reason", ErrAccessTokenExpired.Error())
+		return h.ExpiredResponse, nil
+	case errors.Is(err, ErrAccessTokenInvalid):
+		LoggerFromContext(ctx).Info("account linking required", "reason", ErrAccessTokenInvalid.Error())
+		return h.LinkResponse, nil
+	case err != nil:
+		return nil, err
+	}
+	return h.next(context.WithValue(ctx, linkedUserContextKey{}, user), message)
+}
+
+func (h *AccountLinkingHandler) requiresLink(message *RequestMessage) bool {
+	if h.Intents == nil {
+		return true
+	}
+	request, ok := message.Request.(*IntentRequest)
+	return ok && request.Intent != nil && h.Intents[request.Intent.Name]
+}
+
+func (h *AccountLinkingHandler) next(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	if h.Next == nil {
+		return nil, ErrNoHandler
+	}
+	return h.Next.ServeCEK(ctx, message)
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+var testTokens = map[string]error{
+	"valid":   nil,
+	"expired": fmt.Errorf("token of 2018-06-11: %w", cek.ErrAccessTokenExpired),
+	"invalid": cek.ErrAccessTokenInvalid,
+	"broken":  errors.New("database unavailable"),
+}
+
+func testTokenResolver(calls *int) cek.TokenResolver {
+	return cek.TokenResolverFunc(func(ctx context.Context, accessToken string) (*cek.LinkedUser, error) {
+		*calls++
+		if accessToken == "nouser" {
+			return nil, nil
+		}
+		if err := testTokens[accessToken]; err != nil {
+			return nil, err
+		}
+		return &cek.LinkedUser{ID: "user-" + accessToken}, nil
+	})
+}
+
+func TestAccountLinkingHandler(t *testing.T) {
+	calls := 0
+	h := cek.NewAccountLinkingHandler(testTokenResolver(&calls),
+		cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			id := ""
+			if user := cek.LinkedUserFromContext(ctx); user != nil {
+				id = user.ID
+			}
+			return cek.NewResponseBuilder().SessionAttributes(map[string]string{"user": id}).Build(), nil
+		}),
+		"OrderPizza")
+
+	testCases := []struct {
+		intent       string
+		accessToken  string
+		wantUser     string
+		wantResponse *cek.ResponseMessage
+		wantErr      bool
+	}{
+		{intent: "OrderPizza", accessToken: "valid", wantUser: "user-valid"},
+		{intent: "OrderPizza", wantResponse: h.LinkResponse},
+		{intent: "OrderPizza", accessToken: "invalid", wantResponse: h.LinkResponse},
+		{intent: "OrderPizza", accessToken: "expired", wantResponse: h.ExpiredResponse},
+		{intent: "OrderPizza", accessToken: "nouser", wantResponse: h.LinkResponse},
+		{intent: "OrderPizza", accessToken: "broken", wantErr: true},
+		{intent: "Clova.GuideIntent", wantUser: ""},
+	}
+	for i, testCase := range testCases {
+		message := &cek.RequestMessage{
+			Context: &cek.Context{System: &cek.System{User: &cek.User{UserID: "U1", AccessToken: testCase.accessToken}}},
+			Request: &cek.IntentRequest{Intent: &cek.Intent{Name: testCase.intent}},
+		}
+		response, err := h.ServeCEK(context.Background(), message)
+		if (err != nil) != testCase.wantErr {
+			t.Errorf("Error %d: %v", i, err)
+			continue
+		}
+		switch {
+		case err != nil:
+		case testCase.wantResponse != nil:
+			if response != testCase.wantResponse {
+				t.Errorf("Response %d: %v; want %v", i, response, testCase.wantResponse)
+			}
+		case response.SessionAttributes["user"] != testCase.wantUser:
+			t.Errorf("User %d: %s; want %s", i, response.SessionAttributes["user"], testCase.wantUser)
+		}
+	}
+}
+
+func TestCachingTokenResolver(t *testing.T) {
+	now := time.Date(2018, 6, 11, 9, 19, 23, 0, time.UTC)
+	calls := 0
+	resolver := cek.NewCachingTokenResolver(testTokenResolver(&calls), time.Minute, 10)
+	resolver.SetNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	for _, token := range []string{"valid", "invalid", "expired", "broken"} {
+		for i := 0; i < 2; i++ {
+			_, err := resolver.ResolveToken(ctx, token)
+			if !errors.Is(err, testTokens[token]) {
+				t.Errorf("ResolveToken(%s): %v; want %v", token, err, testTokens[token])
+			}
+		}
+	}
+	// valid and invalid tokens are cached, expired and broken ones are not
+	if calls != 6 {
+		t.Errorf("Calls: %d; want 6", calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := resolver.ResolveToken(ctx, "valid"); err != nil || calls != 7 {
+		t.Errorf("ResolveToken after TTL: %v, %d calls; want 7", err, calls)
+	}
+
+	if user, err := resolver.ResolveToken(ctx, "nouser"); user != nil || !errors.Is(err, cek.ErrAccessTokenInvalid) {
+		t.Errorf("ResolveToken(nouser): %v, %v; want %v", user, err, cek.ErrAccessTokenInvalid)
+	}
+}
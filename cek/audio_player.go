@@ -0,0 +1,293 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+)
+
+// Namespace constants
+const (
+	NamespaceAudioPlayer        = "AudioPlayer"
+	NamespacePlaybackController = "PlaybackController"
+)
+
+// AudioPlayer event names
+const (
+	EventPlayFinished                 = "PlayFinished"
+	EventPlayPaused                   = "PlayPaused"
+	EventPlayResumed                  = "PlayResumed"
+	EventPlayStarted                  = "PlayStarted"
+	EventPlayStopped                  = "PlayStopped"
+	EventProgressReportDelayPassed    = "ProgressReportDelayPassed"
+	EventProgressReportIntervalPassed = "ProgressReportIntervalPassed"
+	EventProgressReportPositionPassed = "ProgressReportPositionPassed"
+	EventStreamRequested              = "StreamRequested"
+)
+
+// PlaybackController event names
+const (
+	EventNextCommandIssued     = "NextCommandIssued"
+	EventPauseCommandIssued    = "PauseCommandIssued"
+	EventPreviousCommandIssued = "PreviousCommandIssued"
+	EventResumeCommandIssued   = "ResumeCommandIssued"
+	EventStopCommandIssued     = "StopCommandIssued"
+)
+
+// Directive names
+const (
+	DirectivePlay          = "Play"
+	DirectiveStreamDeliver = "StreamDeliver"
+	DirectivePause         = "Pause"
+	DirectiveResume        = "Resume"
+	DirectiveStop          = "Stop"
+)
+
+// PlayBehavior type
+type PlayBehavior string
+
+// PlayBehavior constants
+const (
+	PlayBehaviorReplaceAll PlayBehavior = "REPLACE_ALL"
+	PlayBehaviorEnqueue    PlayBehavior = "ENQUEUE"
+)
+
+// AudioPlayerPlayPayload type
+type AudioPlayerPlayPayload struct {
+	AudioItem    *AudioItem   `json:"audioItem"`
+	PlayBehavior PlayBehavior `json:"playBehavior"`
+	Source       *AudioSource `json:"source"`
+}
+
+// AudioItem type
+type AudioItem struct {
+	AudioItemID   string       `json:"audioItemId"`
+	Artist        string       `json:"artist,omitempty"`
+	Stream        *AudioStream `json:"stream"`
+	Title         string       `json:"title,omitempty"`
+	TitleSubText1 string       `json:"titleSubText1,omitempty"`
+	TitleSubText2 string       `json:"titleSubText2,omitempty"`
+}
+
+// AudioStream type
+type AudioStream struct {
+	BeginAtInMilliseconds  int                        `json:"beginAtInMilliseconds"`
+	DurationInMilliseconds int                        `json:"durationInMilliseconds,omitempty"`
+	Format                 string                     `json:"format,omitempty"`
+	ProgressReport         *ProgressReport            `json:"progressReport,omitempty"`
+	Token                  string                     `json:"token"`
+	URL                    string                     `json:"url"`
+	URLPlayable            bool                       `json:"urlPlayable"`
+	Extra                  map[string]json.RawMessage `json:"-"`
+	// the members missing from the decoded stream, which are not written
+	// back
+	noBeginAt, noURLPlayable bool
+}
+
+// UnmarshalJSON method for AudioStream. It notes the members missing from b.
+func (s *AudioStream) UnmarshalJSON(b []byte) error {
+	type alias AudioStream
+	v := struct {
+		*alias
+		BeginAtInMilliseconds *int  `json:"beginAtInMilliseconds"`
+		URLPlayable           *bool `json:"urlPlayable"`
+	}{alias: (*alias)(s)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	s.BeginAtInMilliseconds, s.noBeginAt = 0, v.BeginAtInMilliseconds == nil
+	if v.BeginAtInMilliseconds != nil {
+		s.BeginAtInMilliseconds = *v.BeginAtInMilliseconds
+	}
+	s.URLPlayable, s.noURLPlayable = false, v.URLPlayable == nil
+	if v.URLPlayable != nil {
+		s.URLPlayable = *v.URLPlayable
+	}
+	return nil
+}
+
+// MarshalJSON method for AudioStream. The members in Extra are written as
+// well, and the zero members missing from the decoded stream are not.
+func (s AudioStream) MarshalJSON() ([]byte, error) {
+	type alias AudioStream
+	if !s.noBeginAt && !s.noURLPlayable {
+		return marshalExtra((*alias)(&s), s.Extra)
+	}
+	v := struct {
+		*alias
+		BeginAtInMilliseconds *int  `json:"beginAtInMilliseconds,omitempty"`
+		URLPlayable           *bool `json:"urlPlayable,omitempty"`
+	}{alias: (*alias)(&s)}
+	if !s.noBeginAt || s.BeginAtInMilliseconds != 0 {
+		v.BeginAtInMilliseconds = &s.BeginAtInMilliseconds
+	}
+	if !s.noURLPlayable || s.URLPlayable {
+		v.URLPlayable = &s.URLPlayable
+	}
+	return marshalExtra(&v, s.Extra)
+}
+
+// ProgressReport type
+type ProgressReport struct {
+	ProgressReportDelayInMilliseconds    int                        `json:"progressReportDelayInMilliseconds,omitempty"`
+	ProgressReportIntervalInMilliseconds int                        `json:"progressReportIntervalInMilliseconds,omitempty"`
+	ProgressReportPositionInMilliseconds int                        `json:"progressReportPositionInMilliseconds,omitempty"`
+	Extra                                map[string]json.RawMessage `json:"-"`
+	// the zero members of the decoded report, which are written back
+	zeroDelay, zeroInterval, zeroPosition bool
+}
+
+// UnmarshalJSON method for ProgressReport. It notes the zero members of b.
+func (r *ProgressReport) UnmarshalJSON(b []byte) error {
+	type alias ProgressReport
+	v := struct {
+		*alias
+		ProgressReportDelayInMilliseconds    *int `json:"progressReportDelayInMilliseconds"`
+		ProgressReportIntervalInMilliseconds *int `json:"progressReportIntervalInMilliseconds"`
+		ProgressReportPositionInMilliseconds *int `json:"progressReportPositionInMilliseconds"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	r.ProgressReportDelayInMilliseconds, r.zeroDelay = decodedInt(v.ProgressReportDelayInMilliseconds)
+	r.ProgressReportIntervalInMilliseconds, r.zeroInterval = decodedInt(v.ProgressReportIntervalInMilliseconds)
+	r.ProgressReportPositionInMilliseconds, r.zeroPosition = decodedInt(v.ProgressReportPositionInMilliseconds)
+	return nil
+}
+
+// MarshalJSON method for ProgressReport. The members in Extra are written as
+// well, and so are the zero members of the decoded report.
+func (r ProgressReport) MarshalJSON() ([]byte, error) {
+	type alias ProgressReport
+	if !r.zeroDelay && !r.zeroInterval && !r.zeroPosition {
+		return marshalExtra((*alias)(&r), r.Extra)
+	}
+	v := struct {
+		*alias
+		ProgressReportDelayInMilliseconds    *int `json:"progressReportDelayInMilliseconds,omitempty"`
+		ProgressReportIntervalInMilliseconds *int `json:"progressReportIntervalInMilliseconds,omitempty"`
+		ProgressReportPositionInMilliseconds *int `json:"progressReportPositionInMilliseconds,omitempty"`
+	}{alias: (*alias)(&r)}
+	if r.zeroDelay || r.ProgressReportDelayInMilliseconds != 0 {
+		v.ProgressReportDelayInMilliseconds = &r.ProgressReportDelayInMilliseconds
+	}
+	if r.zeroInterval || r.ProgressReportIntervalInMilliseconds != 0 {
+		v.ProgressReportIntervalInMilliseconds = &r.ProgressReportIntervalInMilliseconds
+	}
+	if r.zeroPosition || r.ProgressReportPositionInMilliseconds != 0 {
+		v.ProgressReportPositionInMilliseconds = &r.ProgressReportPositionInMilliseconds
+	}
+	return marshalExtra(&v, r.Extra)
+}
+
+// decodedInt returns the decoded member and whether it was a zero
+func decodedInt(p *int) (int, bool) {
+	if p == nil {
+		return 0, false
+	}
+	return *p, *p == 0
+}
+
+// AudioSource type
+type AudioSource struct {
+	LogoURL string `json:"logoUrl,omitempty"`
+	Name    string `json:"name"`
+}
+
+// AudioPlayerStreamDeliverPayload type. The payload of StreamRequested events
+// has the same members.
+type AudioPlayerStreamDeliverPayload struct {
+	AudioItemID string       `json:"audioItemId"`
+	AudioStream *AudioStream `json:"audioStream"`
+}
+
+// AudioPlayerEventPayload type is the payload of AudioPlayer events
+type AudioPlayerEventPayload struct {
+	OffsetInMilliseconds int    `json:"offsetInMilliseconds"`
+	Token                string `json:"token"`
+}
+
+// DecodePayload method decodes the payload of the event into v
+func (e *Event) DecodePayload(v interface{}) error {
+	b, err := json.Marshal(e.Payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// NewDirective function
+func NewDirective(namespace, name string, payload interface{}) *Directive {
+	if payload == nil {
+		payload = struct{}{}
+	}
+	return &Directive{
+		Header: &Header{
+			MessageID: NewMessageID(),
+			Name:      name,
+			Namespace: namespace,
+		},
+		Payload: payload,
+	}
+}
+
+// newDirectivePayload returns a pointer to decode the payload of the directive
+// into, or nil when the directive is unknown
+func newDirectivePayload(namespace, name string) interface{} {
+	switch namespace {
+	case NamespaceAudioPlayer:
+		switch name {
+		case DirectivePlay:
+			return &AudioPlayerPlayPayload{}
+		case DirectiveStreamDeliver:
+			return &AudioPlayerStreamDeliverPayload{}
+		}
+	case NamespacePlaybackController:
+		switch name {
+		case DirectivePause, DirectiveResume, DirectiveStop:
+			return &struct{}{}
+		}
+	}
+	return nil
+}
+
+// NewAudioPlayerPlayDirective function
+func NewAudioPlayerPlayDirective(payload *AudioPlayerPlayPayload) *Directive {
+	return NewDirective(NamespaceAudioPlayer, DirectivePlay, payload)
+}
+
+// NewAudioPlayerStreamDeliverDirective function
+func NewAudioPlayerStreamDeliverDirective(payload *AudioPlayerStreamDeliverPayload) *Directive {
+	return NewDirective(NamespaceAudioPlayer, DirectiveStreamDeliver, payload)
+}
+
+// NewPlaybackControllerDirective function returns a Pause, Resume or Stop
+// directive
+func NewPlaybackControllerDirective(name string) *Directive {
+	return NewDirective(NamespacePlaybackController, name, nil)
+}
+
+// NewMessageID function returns a random UUID for the header of a message
+func NewMessageID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
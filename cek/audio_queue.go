@@ -0,0 +1,474 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+)
+
+// RepeatMode type
+type RepeatMode string
+
+// RepeatMode constants
+const (
+	RepeatModeOff RepeatMode = "off"
+	RepeatModeOne RepeatMode = "one"
+	RepeatModeAll RepeatMode = "all"
+)
+
+// AudioTrack type
+type AudioTrack struct {
+	AudioItemID            string `json:"audioItemId"`
+	Artist                 string `json:"artist,omitempty"`
+	DurationInMilliseconds int    `json:"durationInMilliseconds,omitempty"`
+	Title                  string `json:"title,omitempty"`
+	URL                    string `json:"url"`
+}
+
+// AudioQueue type is the playlist of a user on a device. Its members are
+// exported so that stores can serialize it.
+type AudioQueue struct {
+	Tracks []*AudioTrack `json:"tracks"`
+	// Order holds the indexes of Tracks in the play order
+	Order []int `json:"order"`
+	// Position is the index in Order of the current track. It equals
+	// len(Order) once the queue has played to the end.
+	Position             int        `json:"position"`
+	OffsetInMilliseconds int        `json:"offsetInMilliseconds"`
+	Repeat               RepeatMode `json:"repeat"`
+	Shuffled             bool       `json:"shuffled"`
+}
+
+// NewAudioQueue function
+func NewAudioQueue(tracks ...*AudioTrack) *AudioQueue {
+	q := &AudioQueue{Repeat: RepeatModeOff}
+	q.Enqueue(tracks...)
+	return q
+}
+
+// Enqueue method appends the tracks to the end of the play order
+func (q *AudioQueue) Enqueue(tracks ...*AudioTrack) {
+	for _, track := range tracks {
+		q.Order = append(q.Order, len(q.Tracks))
+		q.Tracks = append(q.Tracks, track)
+	}
+}
+
+// Current method returns the current track, or nil when the queue is empty
+// or has played to the end
+func (q *AudioQueue) Current() *AudioTrack {
+	if q.Position < 0 || q.Position >= len(q.Order) {
+		return nil
+	}
+	return q.Tracks[q.Order[q.Position]]
+}
+
+// Next method moves to the next track and returns it. At the end of the
+// queue it wraps around with RepeatModeAll and returns nil otherwise.
+func (q *AudioQueue) Next() *AudioTrack {
+	q.OffsetInMilliseconds = 0
+	if len(q.Order) == 0 {
+		return nil
+	}
+	q.Position++
+	if q.Position >= len(q.Order) {
+		if q.Repeat != RepeatModeAll {
+			q.Position = len(q.Order)
+			return nil
+		}
+		q.Position = 0
+	}
+	return q.Current()
+}
+
+// Previous method moves to the previous track and returns it. At the head
+// of the queue it wraps around with RepeatModeAll and stays on the first
+// track otherwise.
+func (q *AudioQueue) Previous() *AudioTrack {
+	q.OffsetInMilliseconds = 0
+	if len(q.Order) == 0 {
+		return nil
+	}
+	q.Position--
+	if q.Position < 0 {
+		if q.Repeat == RepeatModeAll {
+			q.Position = len(q.Order) - 1
+		} else {
+			q.Position = 0
+		}
+	}
+	return q.Current()
+}
+
+// Finished method returns the track to play after the current one has
+// played to the end, following the repeat mode
+func (q *AudioQueue) Finished() *AudioTrack {
+	if q.Repeat == RepeatModeOne && q.Current() != nil {
+		q.OffsetInMilliseconds = 0
+		return q.Current()
+	}
+	return q.Next()
+}
+
+// Shuffle method turns shuffling on or off. The current track is kept.
+func (q *AudioQueue) Shuffle(on bool) {
+	current := -1
+	if q.Current() != nil {
+		current = q.Order[q.Position]
+	}
+	q.Shuffled = on
+	q.Order = q.Order[:0]
+	for i := range q.Tracks {
+		if i != current {
+			q.Order = append(q.Order, i)
+		}
+	}
+	if on {
+		rand.Shuffle(len(q.Order), func(i, j int) {
+			q.Order[i], q.Order[j] = q.Order[j], q.Order[i]
+		})
+	}
+	if current < 0 {
+		q.Position = 0
+		return
+	}
+	if on {
+		q.Order = append([]int{current}, q.Order...)
+		q.Position = 0
+		return
+	}
+	q.Order = append(q.Order, 0)
+	copy(q.Order[current+1:], q.Order[current:])
+	q.Order[current] = current
+	q.Position = current
+}
+
+// SetRepeat method
+func (q *AudioQueue) SetRepeat(mode RepeatMode) {
+	q.Repeat = mode
+}
+
+func (q *AudioQueue) clone() *AudioQueue {
+	c := *q
+	c.Tracks = append([]*AudioTrack(nil), q.Tracks...)
+	c.Order = append([]int(nil), q.Order...)
+	return &c
+}
+
+// AudioQueueStore interface
+type AudioQueueStore interface {
+	// Load returns nil without an error when there is no queue for the key
+	Load(ctx context.Context, key string) (*AudioQueue, error)
+	Save(ctx context.Context, key string, queue *AudioQueue) error
+}
+
+// MemoryAudioQueueStore type
+type MemoryAudioQueueStore struct {
+	mu     sync.Mutex
+	queues map[string]*AudioQueue
+}
+
+// NewMemoryAudioQueueStore function
+func NewMemoryAudioQueueStore() *MemoryAudioQueueStore {
+	return &MemoryAudioQueueStore{
+		queues: map[string]*AudioQueue{},
+	}
+}
+
+// Load method for implementing AudioQueueStore interface
+func (s *MemoryAudioQueueStore) Load(ctx context.Context, key string) (*AudioQueue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queues[key]
+	if !ok {
+		return nil, nil
+	}
+	return q.clone(), nil
+}
+
+// Save method for implementing AudioQueueStore interface
+func (s *MemoryAudioQueueStore) Save(ctx context.Context, key string, queue *AudioQueue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queues[key] = queue.clone()
+	return nil
+}
+
+// AudioQueueHandler type answers AudioPlayer and PlaybackController events
+// and the next and previous intents from the queue of the user and device.
+// Other requests, and requests of users without a queue, are passed to Next.
+type AudioQueueHandler struct {
+	Store          AudioQueueStore
+	Next           Handler
+	Source         *AudioSource
+	ProgressReport *ProgressReport
+	// Signer signs the stream URLs of Play directives when it is set.
+	// StreamRequested events for the tracks of the queue are then answered
+	// with freshly signed URLs.
+	Signer *StreamURLSigner
+	// Key returns the store key of the request. The user ID and the device
+	// ID are used when it is nil.
+	Key func(message *RequestMessage) string
+}
+
+// NewAudioQueueHandler function
+func NewAudioQueueHandler(store AudioQueueStore, next Handler) *AudioQueueHandler {
+	return &AudioQueueHandler{
+		Store: store,
+		Next:  next,
+	}
+}
+
+// Play method replaces the queue of the request's user and device and
+// returns the response starting its current track
+func (h *AudioQueueHandler) Play(ctx context.Context, message *RequestMessage, queue *AudioQueue) (*ResponseMessage, error) {
+	if err := h.Store.Save(ctx, h.key(message), queue); err != nil {
+		return nil, err
+	}
+	return h.playResponse(queue)
+}
+
+// ServeCEK method for implementing Handler interface
+func (h *AudioQueueHandler) ServeCEK(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	var update func(*AudioQueue) (*ResponseMessage, error)
+	switch request := message.Request.(type) {
+	case *EventRequest:
+		if request.Event != nil {
+			update = h.eventUpdate(request.Event, message)
+		}
+	case *IntentRequest:
+		if request.Intent != nil {
+			switch request.Intent.Name {
+			case BuiltinIntentNext:
+				update = h.next
+			case BuiltinIntentPrevious:
+				update = h.previous
+			}
+		}
+	}
+	if update == nil {
+		return h.serveNext(ctx, message)
+	}
+
+	key := h.key(message)
+	queue, err := h.Store.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if queue == nil {
+		return h.serveNext(ctx, message)
+	}
+	response, err := update(queue)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.Store.Save(ctx, key, queue); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (h *AudioQueueHandler) eventUpdate(event *Event, message *RequestMessage) func(*AudioQueue) (*ResponseMessage, error) {
+	switch event.Namespace {
+	case NamespaceAudioPlayer:
+		switch event.Name {
+		case EventPlayStarted, EventPlayResumed, EventPlayPaused, EventPlayStopped,
+			EventProgressReportDelayPassed, EventProgressReportIntervalPassed, EventProgressReportPositionPassed:
+			return func(queue *AudioQueue) (*ResponseMessage, error) {
+				payload := &AudioPlayerEventPayload{}
+				if err := event.DecodePayload(payload); err != nil {
+					return nil, err
+				}
+				if current := queue.Current(); current != nil && current.AudioItemID == payload.Token {
+					queue.OffsetInMilliseconds = payload.OffsetInMilliseconds
+				}
+				return NewResponseBuilder().Build(), nil
+			}
+		case EventStreamRequested:
+			if h.Signer == nil {
+				return nil
+			}
+			return func(queue *AudioQueue) (*ResponseMessage, error) {
+				return h.streamDeliverResponse(queue, event)
+			}
+		case EventPlayFinished:
+			return func(queue *AudioQueue) (*ResponseMessage, error) {
+				payload := &AudioPlayerEventPayload{}
+				if err := event.DecodePayload(payload); err != nil {
+					return nil, err
+				}
+				// late or repeated events of another track must not skip one
+				if current := queue.Current(); current == nil || current.AudioItemID != payload.Token {
+					return NewResponseBuilder().Build(), nil
+				}
+				if queue.Finished() == nil {
+					return NewResponseBuilder().Build(), nil
+				}
+				return h.playResponse(queue)
+			}
+		}
+	case NamespacePlaybackController:
+		switch event.Name {
+		case EventNextCommandIssued:
+			return h.next
+		case EventPreviousCommandIssued:
+			return h.previous
+		case EventResumeCommandIssued:
+			return func(queue *AudioQueue) (*ResponseMessage, error) {
+				current := queue.Current()
+				if current == nil {
+					return NewResponseBuilder().Build(), nil
+				}
+				// resume from the position reported by the device unless it
+				// is playing another stream
+				if message.Context != nil && message.Context.AudioPlayer != nil {
+					player := message.Context.AudioPlayer
+					if token := player.CurrentToken(); token == "" || token == current.AudioItemID {
+						queue.OffsetInMilliseconds = player.OffsetInMilliseconds
+					}
+				}
+				return h.playResponse(queue)
+			}
+		case EventPauseCommandIssued:
+			return h.control(DirectivePause)
+		case EventStopCommandIssued:
+			return h.control(DirectiveStop)
+		}
+	}
+	return nil
+}
+
+func (h *AudioQueueHandler) next(queue *AudioQueue) (*ResponseMessage, error) {
+	if queue.Next() == nil {
+		return NewResponseBuilder().ShouldEndSession(true).Build(), nil
+	}
+	return h.playResponse(queue)
+}
+
+func (h *AudioQueueHandler) previous(queue *AudioQueue) (*ResponseMessage, error) {
+	if queue.Previous() == nil {
+		return NewResponseBuilder().ShouldEndSession(true).Build(), nil
+	}
+	return h.playResponse(queue)
+}
+
+func (h *AudioQueueHandler) control(name string) func(*AudioQueue) (*ResponseMessage, error) {
+	return func(queue *AudioQueue) (*ResponseMessage, error) {
+		return NewResponseBuilder().
+			AddDirective(NewPlaybackControllerDirective(name)).
+			ShouldEndSession(true).
+			Build(), nil
+	}
+}
+
+func (h *AudioQueueHandler) playResponse(queue *AudioQueue) (*ResponseMessage, error) {
+	track := queue.Current()
+	if track == nil {
+		return NewResponseBuilder().ShouldEndSession(true).Build(), nil
+	}
+	stream, err := h.stream(track, queue.OffsetInMilliseconds)
+	if err != nil {
+		return nil, err
+	}
+	return NewResponseBuilder().
+		AddDirective(NewAudioPlayerPlayDirective(&AudioPlayerPlayPayload{
+			AudioItem: &AudioItem{
+				AudioItemID: track.AudioItemID,
+				Artist:      track.Artist,
+				Title:       track.Title,
+				Stream:      stream,
+			},
+			PlayBehavior: PlayBehaviorReplaceAll,
+			Source:       h.Source,
+		})).
+		ShouldEndSession(true).
+		Build(), nil
+}
+
+func (h *AudioQueueHandler) streamDeliverResponse(queue *AudioQueue, event *Event) (*ResponseMessage, error) {
+	payload := &AudioPlayerStreamDeliverPayload{}
+	if err := event.DecodePayload(payload); err != nil {
+		return nil, err
+	}
+	var stream *AudioStream
+	for _, track := range queue.Tracks {
+		if track.AudioItemID != payload.AudioItemID {
+			continue
+		}
+		beginAt := 0
+		if payload.AudioStream != nil {
+			beginAt = payload.AudioStream.BeginAtInMilliseconds
+		}
+		s, err := h.stream(track, beginAt)
+		if err != nil {
+			return nil, err
+		}
+		stream = s
+		break
+	}
+	if stream == nil {
+		// only the tracks of the queue are signed, so that the URL of the
+		// event cannot get any path of the media server signed
+		return NewResponseBuilder().Build(), nil
+	}
+	return NewResponseBuilder().
+		AddDirective(NewAudioPlayerStreamDeliverDirective(&AudioPlayerStreamDeliverPayload{
+			AudioItemID: payload.AudioItemID,
+			AudioStream: stream,
+		})).
+		Build(), nil
+}
+
+func (h *AudioQueueHandler) stream(track *AudioTrack, beginAt int) (*AudioStream, error) {
+	stream := &AudioStream{
+		BeginAtInMilliseconds:  beginAt,
+		DurationInMilliseconds: track.DurationInMilliseconds,
+		ProgressReport:         h.ProgressReport,
+		Token:                  track.AudioItemID,
+		URL:                    track.URL,
+		URLPlayable:            true,
+	}
+	if h.Signer != nil {
+		if err := h.Signer.SignStream(stream); err != nil {
+			return nil, err
+		}
+	}
+	return stream, nil
+}
+
+func (h *AudioQueueHandler) serveNext(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	if h.Next == nil {
+		return nil, ErrNoHandler
+	}
+	return h.Next.ServeCEK(ctx, message)
+}
+
+func (h *AudioQueueHandler) key(message *RequestMessage) string {
+	if h.Key != nil {
+		return h.Key(message)
+	}
+	var userID, deviceID string
+	if message.Context != nil && message.Context.System != nil {
+		if message.Context.System.User != nil {
+			userID = message.Context.System.User.UserID
+		}
+		if message.Context.System.Device != nil {
+			deviceID = message.Context.System.Device.DeviceID
+		}
+	}
+	return userID + "/" + deviceID
+}
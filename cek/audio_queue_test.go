@@ -0,0 +1,194 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+var testTracks = []*cek.AudioTrack{
+	{AudioItemID: "track-1", Title: "One", URL: "https://DUMMY_DOMAIN/1.mp3"},
+	{AudioItemID: "track-2", Title: "Two", URL: "https://DUMMY_DOMAIN/2.mp3"},
+	{AudioItemID: "track-3", Title: "Three", URL: "https://DUMMY_DOMAIN/3.mp3"},
+}
+
+func trackIDs(q *cek.AudioQueue, moves func(*cek.AudioQueue) *cek.AudioTrack, n int) []string {
+	var ids []string
+	for i := 0; i < n; i++ {
+		track := moves(q)
+		if track == nil {
+			ids = append(ids, "")
+			continue
+		}
+		ids = append(ids, track.AudioItemID)
+	}
+	return ids
+}
+
+func TestAudioQueue(t *testing.T) {
+	q := cek.NewAudioQueue(testTracks...)
+	if got := trackIDs(q, (*cek.AudioQueue).Next, 3); !reflect.DeepEqual(got, []string{"track-2", "track-3", ""}) {
+		t.Errorf("Next: %v", got)
+	}
+	if got := trackIDs(q, (*cek.AudioQueue).Previous, 4); !reflect.DeepEqual(got, []string{"track-3", "track-2", "track-1", "track-1"}) {
+		t.Errorf("Previous: %v", got)
+	}
+
+	q.SetRepeat(cek.RepeatModeAll)
+	if got := trackIDs(q, (*cek.AudioQueue).Previous, 1); !reflect.DeepEqual(got, []string{"track-3"}) {
+		t.Errorf("Previous with RepeatModeAll: %v", got)
+	}
+	if got := trackIDs(q, (*cek.AudioQueue).Finished, 2); !reflect.DeepEqual(got, []string{"track-1", "track-2"}) {
+		t.Errorf("Finished with RepeatModeAll: %v", got)
+	}
+	q.SetRepeat(cek.RepeatModeOne)
+	if got := trackIDs(q, (*cek.AudioQueue).Finished, 2); !reflect.DeepEqual(got, []string{"track-2", "track-2"}) {
+		t.Errorf("Finished with RepeatModeOne: %v", got)
+	}
+
+	q.Enqueue(&cek.AudioTrack{AudioItemID: "track-4"}, &cek.AudioTrack{AudioItemID: "track-5"})
+	q.Shuffle(true)
+	if q.Current().AudioItemID != "track-2" || q.Position != 0 {
+		t.Errorf("Shuffle changed the current track: %v at %d", q.Current(), q.Position)
+	}
+	order := append([]int(nil), q.Order...)
+	sort.Ints(order)
+	if !reflect.DeepEqual(order, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("Shuffled order %v is not a permutation", q.Order)
+	}
+	q.Shuffle(false)
+	if !reflect.DeepEqual(q.Order, []int{0, 1, 2, 3, 4}) || q.Current().AudioItemID != "track-2" {
+		t.Errorf("Unshuffled order %v at %d", q.Order, q.Position)
+	}
+}
+
+func newAudioRequest(request cek.Request, offset int) *cek.RequestMessage {
+	return &cek.RequestMessage{
+		Context: &cek.Context{
+			AudioPlayer: &cek.AudioPlayer{
+				OffsetInMilliseconds: offset,
+				PlayerActivity:       cek.PlayerActivityPAUSED,
+			},
+			System: &cek.System{
+				Application: &cek.Application{ApplicationID: "com.yourdomain.extension.music"},
+				Device:      &cek.Device{DeviceID: "096e6b27-1717-33e9-b0a7-510a48658a9b"},
+				User:        &cek.User{UserID: "U399a1e08a8d474521fc4bbd8c7b4148f"},
+			},
+		},
+		Request: request,
+		Version: "1.0",
+	}
+}
+
+func playedStream(t *testing.T, response *cek.ResponseMessage) *cek.AudioStream {
+	t.Helper()
+	if len(response.Response.Directives) != 1 {
+		t.Fatalf("Directives: %v", response.Response.Directives)
+	}
+	directive := response.Response.Directives[0]
+	if directive.Header.Namespace != cek.NamespaceAudioPlayer || directive.Header.Name != cek.DirectivePlay {
+		t.Fatalf("Directive: %v", directive.Header)
+	}
+	return directive.Payload.(*cek.AudioPlayerPlayPayload).AudioItem.Stream
+}
+
+func TestAudioQueueHandler(t *testing.T) {
+	ctx := context.Background()
+	var passed int
+	h := cek.NewAudioQueueHandler(cek.NewMemoryAudioQueueStore(), cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+		passed++
+		return cek.NewResponseBuilder().Build(), nil
+	}))
+
+	// no queue yet
+	if _, err := h.ServeCEK(ctx, newAudioRequest(&cek.IntentRequest{Intent: &cek.Intent{Name: "Clova.NextIntent"}}, 0)); err != nil || passed != 1 {
+		t.Fatalf("ServeCEK without queue: %v, passed %d", err, passed)
+	}
+
+	response, err := h.Play(ctx, newAudioRequest(&cek.LaunchRequest{}, 0), cek.NewAudioQueue(testTracks...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stream := playedStream(t, response); stream.Token != "track-1" || stream.URL != "https://DUMMY_DOMAIN/1.mp3" {
+		t.Errorf("Play: %v", stream)
+	}
+
+	testCases := []struct {
+		request    cek.Request
+		offset     int
+		wantToken  string
+		wantOffset int
+		wantPassed int
+	}{
+		{
+			request:    &cek.EventRequest{Event: &cek.Event{Namespace: "AudioPlayer", Name: "PlayFinished", Payload: map[string]interface{}{"token": "track-1", "offsetInMilliseconds": 180000}}},
+			wantToken:  "track-2",
+			wantPassed: 1,
+		},
+		{
+			request:    &cek.EventRequest{Event: &cek.Event{Namespace: "AudioPlayer", Name: "PlayFinished", Payload: map[string]interface{}{"token": "track-1", "offsetInMilliseconds": 180000}}},
+			wantPassed: 1,
+		},
+		{
+			request:    &cek.IntentRequest{Intent: &cek.Intent{Name: "Clova.NextIntent"}},
+			wantToken:  "track-3",
+			wantPassed: 1,
+		},
+		{
+			request:    &cek.EventRequest{Event: &cek.Event{Namespace: "PlaybackController", Name: "PreviousCommandIssued"}},
+			wantToken:  "track-2",
+			wantPassed: 1,
+		},
+		{
+			request:    &cek.EventRequest{Event: &cek.Event{Namespace: "AudioPlayer", Name: "PlayPaused", Payload: map[string]interface{}{"token": "track-2", "offsetInMilliseconds": 42000}}},
+			wantPassed: 1,
+		},
+		{
+			request:    &cek.EventRequest{Event: &cek.Event{Namespace: "PlaybackController", Name: "ResumeCommandIssued"}},
+			offset:     43000,
+			wantToken:  "track-2",
+			wantOffset: 43000,
+			wantPassed: 1,
+		},
+		{
+			request:    &cek.IntentRequest{Intent: &cek.Intent{Name: "OrderPizza"}},
+			wantPassed: 2,
+		},
+	}
+	for i, testCase := range testCases {
+		response, err := h.ServeCEK(ctx, newAudioRequest(testCase.request, testCase.offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if passed != testCase.wantPassed {
+			t.Errorf("Request %d: passed %d; want %d", i, passed, testCase.wantPassed)
+		}
+		if testCase.wantToken == "" {
+			if len(response.Response.Directives) != 0 {
+				t.Errorf("Request %d: directives %v", i, response.Response.Directives)
+			}
+			continue
+		}
+		stream := playedStream(t, response)
+		if stream.Token != testCase.wantToken || stream.BeginAtInMilliseconds != testCase.wantOffset {
+			t.Errorf("Request %d: token %s at %d; want %s at %d", i, stream.Token, stream.BeginAtInMilliseconds, testCase.wantToken, testCase.wantOffset)
+		}
+	}
+}
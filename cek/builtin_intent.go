@@ -0,0 +1,119 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"sync"
+)
+
+// Built-in intent names
+const (
+	BuiltinIntentCancel   = "Clova.CancelIntent"
+	BuiltinIntentGuide    = "Clova.GuideIntent"
+	BuiltinIntentNext     = "Clova.NextIntent"
+	BuiltinIntentNo       = "Clova.NoIntent"
+	BuiltinIntentPause    = "Clova.PauseIntent"
+	BuiltinIntentPrevious = "Clova.PreviousIntent"
+	BuiltinIntentResume   = "Clova.ResumeIntent"
+	BuiltinIntentStop     = "Clova.StopIntent"
+	BuiltinIntentYes      = "Clova.YesIntent"
+)
+
+// SessionAttributePendingConfirmation is the session attribute holding the
+// name of the confirmation waiting for Clova.YesIntent or Clova.NoIntent
+const SessionAttributePendingConfirmation = "cek.pendingConfirmation"
+
+// CancelIntentHandler function returns a handler ending the session with the
+// speech. The session ends silently when speech is nil.
+func CancelIntentHandler(speech *OutputSpeech) Handler {
+	return HandlerFunc(func(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+		return NewResponseBuilder().
+			OutputSpeech(speech).
+			ShouldEndSession(true).
+			Build(), nil
+	})
+}
+
+// GuideIntentHandler function returns a handler playing the help speech and
+// keeping the session open. The session attributes of the request are kept.
+func GuideIntentHandler(speech *OutputSpeech) Handler {
+	return HandlerFunc(func(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+		b := NewResponseBuilder().
+			OutputSpeech(speech).
+			Reprompt(speech)
+		if message.Session != nil && message.Session.SessionAttributes != nil {
+			b.SessionAttributes(message.Session.SessionAttributes)
+		}
+		return b.Build(), nil
+	})
+}
+
+// PendingConfirmation method stores the name of a confirmation in the session
+// attributes, so that the next Clova.YesIntent or Clova.NoIntent resolves it
+func (b *ResponseBuilder) PendingConfirmation(name string) *ResponseBuilder {
+	attributes := make(map[string]string, len(b.sessionAttributes)+1)
+	for k, v := range b.sessionAttributes {
+		attributes[k] = v
+	}
+	attributes[SessionAttributePendingConfirmation] = name
+	b.sessionAttributes = attributes
+	return b
+}
+
+// ConfirmationFunc type resolves a pending confirmation
+type ConfirmationFunc func(ctx context.Context, message *RequestMessage, confirmed bool) (*ResponseMessage, error)
+
+// ConfirmationHandler type resolves the pending confirmation stored in the
+// session when Clova.YesIntent or Clova.NoIntent arrives. Register it for
+// both intents. Requests without a pending confirmation go to NoPending,
+// and fail with ErrNoHandler when it is nil.
+type ConfirmationHandler struct {
+	NoPending Handler
+
+	mu            sync.RWMutex
+	confirmations map[string]ConfirmationFunc
+}
+
+// NewConfirmationHandler function
+func NewConfirmationHandler() *ConfirmationHandler {
+	return &ConfirmationHandler{
+		confirmations: map[string]ConfirmationFunc{},
+	}
+}
+
+// Handle method registers f for the confirmation
+func (h *ConfirmationHandler) Handle(name string, f ConfirmationFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.confirmations[name] = f
+}
+
+// ServeCEK method for implementing Handler interface
+func (h *ConfirmationHandler) ServeCEK(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	request, ok := message.Request.(*IntentRequest)
+	if ok && request.Intent != nil && message.Session != nil {
+		h.mu.RLock()
+		f, found := h.confirmations[message.Session.SessionAttributes[SessionAttributePendingConfirmation]]
+		h.mu.RUnlock()
+		if found {
+			return f(ctx, message, request.Intent.Name == BuiltinIntentYes)
+		}
+	}
+	if h.NoPending == nil {
+		return nil, ErrNoHandler
+	}
+	return h.NoPending.ServeCEK(ctx, message)
+}
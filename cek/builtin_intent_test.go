@@ -0,0 +1,105 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestBuiltinIntentHandlers(t *testing.T) {
+	ctx := context.Background()
+	goodbye := cek.NewOutputSpeechBuilder().AddSpeechText("またね", cek.SpeechInfoLangJA).Build()
+	help := cek.NewOutputSpeechBuilder().AddSpeechText("ピザを注文できます", cek.SpeechInfoLangJA).Build()
+
+	mux := cek.NewServeMux()
+	mux.HandleIntent(cek.BuiltinIntentCancel, cek.CancelIntentHandler(goodbye))
+	mux.HandleIntent(cek.BuiltinIntentGuide, cek.GuideIntentHandler(help))
+
+	message := &cek.RequestMessage{
+		Request: &cek.IntentRequest{Intent: &cek.Intent{Name: cek.BuiltinIntentCancel}},
+		Session: &cek.Session{SessionAttributes: map[string]string{"pizzaType": "ペパロニ"}},
+	}
+	response, err := mux.ServeCEK(ctx, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Response.ShouldEndSession || response.Response.OutputSpeech != goodbye {
+		t.Errorf("Cancel response: %v", response.Response)
+	}
+
+	message.Request = &cek.IntentRequest{Intent: &cek.Intent{Name: cek.BuiltinIntentGuide}}
+	response, err = mux.ServeCEK(ctx, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Response.ShouldEndSession || response.Response.OutputSpeech != help || response.Response.Reprompt.OutputSpeech != help {
+		t.Errorf("Guide response: %v", response.Response)
+	}
+	if !reflect.DeepEqual(response.SessionAttributes, message.Session.SessionAttributes) {
+		t.Errorf("Guide session attributes: %v", response.SessionAttributes)
+	}
+}
+
+func TestConfirmationHandler(t *testing.T) {
+	ctx := context.Background()
+	confirmations := cek.NewConfirmationHandler()
+	confirmations.Handle("order", func(ctx context.Context, message *cek.RequestMessage, confirmed bool) (*cek.ResponseMessage, error) {
+		return cek.NewResponseBuilder().
+			SessionAttributes(map[string]string{"result": fmt.Sprint(confirmed)}).
+			Build(), nil
+	})
+	mux := cek.NewServeMux()
+	mux.HandleIntent(cek.BuiltinIntentYes, confirmations)
+	mux.HandleIntent(cek.BuiltinIntentNo, confirmations)
+
+	pending := cek.NewResponseBuilder().
+		SessionAttributes(map[string]string{"pizzaType": "ペパロニ"}).
+		PendingConfirmation("order").
+		Build()
+	want := map[string]string{"pizzaType": "ペパロニ", cek.SessionAttributePendingConfirmation: "order"}
+	if !reflect.DeepEqual(pending.SessionAttributes, want) {
+		t.Errorf("Pending session attributes: %v; want %v", pending.SessionAttributes, want)
+	}
+
+	testCases := []struct {
+		intent     string
+		attributes map[string]string
+		wantResult string
+		wantErr    error
+	}{
+		{intent: cek.BuiltinIntentYes, attributes: pending.SessionAttributes, wantResult: "true"},
+		{intent: cek.BuiltinIntentNo, attributes: pending.SessionAttributes, wantResult: "false"},
+		{intent: cek.BuiltinIntentYes, attributes: map[string]string{}, wantErr: cek.ErrNoHandler},
+	}
+	for i, testCase := range testCases {
+		response, err := mux.ServeCEK(ctx, &cek.RequestMessage{
+			Request: &cek.IntentRequest{Intent: &cek.Intent{Name: testCase.intent}},
+			Session: &cek.Session{SessionAttributes: testCase.attributes},
+		})
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("Error %d: %v; want %v", i, err, testCase.wantErr)
+			continue
+		}
+		if err == nil && response.SessionAttributes["result"] != testCase.wantResult {
+			t.Errorf("Result %d: %v; want %s", i, response.SessionAttributes, testCase.wantResult)
+		}
+	}
+}
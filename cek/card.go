@@ -0,0 +1,141 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// CardType type is the type of a content template shown on the devices with a
+// screen
+type CardType string
+
+// CardType constants
+const (
+	CardTypeImageText CardType = "ImageText"
+	CardTypeImageList CardType = "ImageList"
+)
+
+// CardFieldType type
+type CardFieldType string
+
+// CardFieldType constants
+const (
+	CardFieldTypeString CardFieldType = "string"
+	CardFieldTypeURL    CardFieldType = "url"
+)
+
+// CardField type is a value of a content template
+type CardField struct {
+	Type  CardFieldType `json:"type"`
+	Value string        `json:"value"`
+}
+
+// ImageTextCard type is the ImageText content template. The members of the
+// template it does not have are kept in Extra.
+type ImageTextCard struct {
+	ImageURL      *CardField                 `json:"imageUrl,omitempty"`
+	MainText      *CardField                 `json:"mainText,omitempty"`
+	ReferenceText *CardField                 `json:"referenceText,omitempty"`
+	ReferenceURL  *CardField                 `json:"referenceUrl,omitempty"`
+	SubTextList   []*CardField               `json:"subTextList,omitempty"`
+	ThumbImageURL *CardField                 `json:"thumbImageUrl,omitempty"`
+	Extra         map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for ImageTextCard. The card is written with its type, and
+// the members in Extra are written as well.
+func (c ImageTextCard) MarshalJSON() ([]byte, error) {
+	type alias ImageTextCard
+	return marshalExtra(&struct {
+		Type CardType `json:"type"`
+		*alias
+	}{
+		Type:  CardTypeImageText,
+		alias: (*alias)(&c),
+	}, c.Extra)
+}
+
+// ImageListCard type is the ImageList content template. The members of the
+// template it does not have are kept in Extra.
+type ImageListCard struct {
+	ImageList []*ImageListItem           `json:"imageList"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for ImageListCard. The card is written with its type, and
+// the members in Extra are written as well.
+func (c ImageListCard) MarshalJSON() ([]byte, error) {
+	type alias ImageListCard
+	return marshalExtra(&struct {
+		Type CardType `json:"type"`
+		*alias
+	}{
+		Type:  CardTypeImageList,
+		alias: (*alias)(&c),
+	}, c.Extra)
+}
+
+// ImageListItem type is an image of ImageListCard
+type ImageListItem struct {
+	ImageURL      *CardField                 `json:"imageUrl,omitempty"`
+	ReferenceText *CardField                 `json:"referenceText,omitempty"`
+	ReferenceURL  *CardField                 `json:"referenceUrl,omitempty"`
+	ThumbImageURL *CardField                 `json:"thumbImageUrl,omitempty"`
+	Extra         map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for ImageListItem. The members in Extra are written as
+// well.
+func (i ImageListItem) MarshalJSON() ([]byte, error) {
+	type alias ImageListItem
+	return marshalExtra((*alias)(&i), i.Extra)
+}
+
+func newCard(cardType CardType) interface{} {
+	switch cardType {
+	case CardTypeImageText:
+		return &ImageTextCard{}
+	case CardTypeImageList:
+		return &ImageListCard{}
+	}
+	return nil
+}
+
+// unmarshalCard decodes the cards of the types this package has into their
+// structs, and the others as into an interface{}
+func unmarshalCard(b json.RawMessage) (interface{}, error) {
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return nil, nil
+	}
+	var header struct {
+		Type CardType `json:"type"`
+	}
+	var card interface{}
+	if json.Unmarshal(b, &header) == nil {
+		card = newCard(header.Type)
+	}
+	if card == nil {
+		err := json.Unmarshal(b, &card)
+		return card, err
+	}
+	if err := json.Unmarshal(b, card); err != nil {
+		return nil, err
+	}
+	collectExtra(b, skipSpace(b, 0), reflect.ValueOf(card).Elem(), "type")
+	return card, nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package cektest provides helpers for testing Clova extensions.
+package cektest
+
+import (
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+// AssertValidResponse function fails the test with an error per violation
+// of the response message, like a linter would
+func AssertValidResponse(t testing.TB, message *cek.ResponseMessage) {
+	t.Helper()
+	if message == nil {
+		t.Error("response message is nil")
+		return
+	}
+	for _, v := range message.Validate() {
+		t.Errorf("invalid response: %s", v)
+	}
+}
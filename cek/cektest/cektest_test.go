@@ -0,0 +1,63 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cektest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+	"github.com/line/clova-cek-sdk-go/cek/cektest"
+)
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Error(args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertValidResponse(t *testing.T) {
+	valid := cek.NewResponseBuilder().
+		OutputSpeech(cek.NewOutputSpeechBuilder().AddSpeechText("こんにちは", cek.SpeechInfoLangJA).Build()).
+		Build()
+	rt := &recordingT{}
+	cektest.AssertValidResponse(rt, valid)
+	if len(rt.errors) != 0 {
+		t.Errorf("errors for a valid response: %v", rt.errors)
+	}
+
+	invalid := cek.NewResponseBuilder().
+		OutputSpeech(cek.NewOutputSpeechBuilder().AddSpeechURL("http://example.com/a.mp3").Build()).
+		Reprompt(cek.NewOutputSpeechBuilder().Build()).
+		Build()
+	rt = &recordingT{}
+	cektest.AssertValidResponse(rt, invalid)
+	want := []string{
+		`invalid response: response.outputSpeech.values.value: "http://example.com/a.mp3" is not an HTTPS URL`,
+		"invalid response: response.reprompt.outputSpeech.values: SpeechList is empty",
+	}
+	if fmt.Sprint(rt.errors) != fmt.Sprint(want) {
+		t.Errorf("errors: %q; want %q", rt.errors, want)
+	}
+}
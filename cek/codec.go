@@ -0,0 +1,137 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Codec interface encodes the response messages and decodes the request
+// messages of an extension. The requests are decoded into a struct without
+// UnmarshalJSON method, so the codec decodes the whole body itself.
+// Implementations must call the UnmarshalJSON and MarshalJSON methods of the
+// nested types, as encoding/json does.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec type is the Codec of encoding/json, used by default
+type JSONCodec struct {
+	// DisallowUnknownFields makes Unmarshal fail with an UnknownFieldsError for
+	// the request messages having members the SDK does not know
+	DisallowUnknownFields bool
+}
+
+// Marshal method for implementing Codec interface
+func (c JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal method for implementing Codec interface
+func (c JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	message, ok := v.(*RequestMessage)
+	if !ok || !c.DisallowUnknownFields {
+		return json.Unmarshal(data, v)
+	}
+	if err := UnmarshalRequestMessage(data, message); err != nil {
+		return err
+	}
+	if fields := message.UnknownFields(); len(fields) > 0 {
+		return &UnknownFieldsError{Fields: fields}
+	}
+	return nil
+}
+
+// UnknownFieldsError type is returned by JSONCodec with DisallowUnknownFields
+// for the members the SDK does not know
+type UnknownFieldsError struct {
+	Fields []string
+}
+
+// Error method for implementing error interface
+func (e *UnknownFieldsError) Error() string {
+	return "unknown fields " + strings.Join(e.Fields, ", ")
+}
+
+// WithCodec function sets the codec of the request and response messages
+func WithCodec(codec Codec) ExtensionOption {
+	return func(ext *Extension) {
+		ext.codec = codec
+	}
+}
+
+// decodeError wraps the errors of the codec, which are classified as decode
+// errors whatever their types
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("decode request: %v", e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// decodeRequest decodes body into message with the codec, and collects the
+// unknown members when they are kept or disallowed
+func (e *Extension) decodeRequest(body []byte, message *RequestMessage) error {
+	codec := e.getCodec()
+	fields := &requestMessageFields{}
+	if err := codec.Unmarshal(body, fields); err != nil {
+		return err
+	}
+	if err := fields.message(message); err != nil {
+		return err
+	}
+	disallow := disallowsUnknownFields(codec)
+	if !disallow && !e.extraMembers && !e.strictDecoding {
+		return nil
+	}
+	// the unknown members are found by a scan expecting valid JSON, which
+	// other codecs may accept less strictly than encoding/json. The error of
+	// encoding/json is returned for the bodies it rejects.
+	if _, ok := codec.(JSONCodec); !ok && !json.Valid(body) {
+		return json.Unmarshal(body, &struct{}{})
+	}
+	collectMessageExtra(body, message)
+	if disallow {
+		if fields := message.UnknownFields(); len(fields) > 0 {
+			return &UnknownFieldsError{Fields: fields}
+		}
+	}
+	return nil
+}
+
+func disallowsUnknownFields(codec Codec) bool {
+	switch c := codec.(type) {
+	case JSONCodec:
+		return c.DisallowUnknownFields
+	case *JSONCodec:
+		return c.DisallowUnknownFields
+	}
+	return false
+}
+
+func (e *Extension) getCodec() Codec {
+	if e.codec == nil {
+		return JSONCodec{}
+	}
+	return e.codec
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+type countingCodec struct {
+	cek.JSONCodec
+	marshaled, unmarshaled int
+	// decodedItself counts the values decoding themselves with encoding/json
+	decodedItself int
+	err           error
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshaled++
+	return c.JSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshaled++
+	if _, ok := v.(json.Unmarshaler); ok {
+		c.decodedItself++
+	}
+	if c.err != nil {
+		return c.err
+	}
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+func TestWithCodec(t *testing.T) {
+	codec := &countingCodec{}
+	var intent string
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithCodec(codec),
+		cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			intent = message.Request.(*cek.IntentRequest).Intent.Name
+			return cek.NewResponseBuilder().
+				OutputSpeech(cek.NewOutputSpeechBuilder().AddSpeechText("はい", cek.SpeechInfoLangJA).Build()).
+				Build(), nil
+		})))
+
+	w := httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1])))
+	if w.Code != 200 || intent != "OrderPizza" {
+		t.Errorf("Status: %d, intent %q", w.Code, intent)
+	}
+	if codec.unmarshaled != 1 || codec.marshaled != 1 || codec.decodedItself != 0 {
+		t.Errorf("codec calls: unmarshal %d (%d decoding themselves), marshal %d", codec.unmarshaled, codec.decodedItself, codec.marshaled)
+	}
+	response := &cek.ResponseMessage{}
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil {
+		t.Fatal(err)
+	}
+
+	codec.err = errors.New("codec error")
+	w = httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1])))
+	if w.Code != 400 {
+		t.Errorf("Status: %d; want 400", w.Code)
+	}
+}
+
+func TestWithCodecExtraMembers(t *testing.T) {
+	codec := &countingCodec{}
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithCodec(codec),
+		cek.WithExtraMembers)
+	body := strings.Replace(testRequestBodies[1], `"type": "IntentRequest",`, `"type": "IntentRequest", "locale": "ja-JP",`, 1)
+	message, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(message.Request.(*cek.IntentRequest).Extra["locale"]); got != `"ja-JP"` || codec.decodedItself != 0 {
+		t.Errorf(`Extra["locale"] = %s, %d values decoding themselves`, got, codec.decodedItself)
+	}
+}
+
+func TestJSONCodecDisallowUnknownFields(t *testing.T) {
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithCodec(cek.JSONCodec{DisallowUnknownFields: true}))
+	if _, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1]))); err != nil {
+		t.Errorf("ParseRequest: %v", err)
+	}
+
+	body := strings.Replace(testRequestBodies[1], `"type": "IntentRequest",`, `"type": "IntentRequest", "locale": "ja-JP",`, 1)
+	_, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	var unknownFields *cek.UnknownFieldsError
+	if !errors.As(err, &unknownFields) || !reflect.DeepEqual(unknownFields.Fields, []string{"request.locale"}) {
+		t.Errorf("ParseRequest: %v; want %T", err, unknownFields)
+	}
+
+	for _, strict := range []bool{false, true} {
+		codec := cek.JSONCodec{DisallowUnknownFields: strict}
+		if err := codec.Unmarshal([]byte(testRequestBodies[1]+"garbage"), &cek.RequestMessage{}); err == nil {
+			t.Errorf("Unmarshal (strict %v) accepts trailing data", strict)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"io"
+)
+
+type requestContextKey struct{}
+
+type requestContext struct {
+	message *RequestMessage
+	body    []byte
+}
+
+// NewRequestContext function returns a copy of ctx carrying the request
+// message and its raw body. Extension.ServeHTTP passes such a context to
+// handlers; it is exported for dispatching handlers by other means.
+func NewRequestContext(ctx context.Context, message *RequestMessage, body []byte) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, &requestContext{
+		message: message,
+		body:    body,
+	})
+}
+
+// RequestFromContext function returns the request message carried by ctx, or
+// nil
+func RequestFromContext(ctx context.Context) *RequestMessage {
+	if rc, ok := ctx.Value(requestContextKey{}).(*requestContext); ok {
+		return rc.message
+	}
+	return nil
+}
+
+// ApplicationIDFromContext function returns the application ID of the request
+// carried by ctx, or an empty string
+func ApplicationIDFromContext(ctx context.Context) string {
+	message := RequestFromContext(ctx)
+	if message == nil || message.Context == nil || message.Context.System == nil || message.Context.System.Application == nil {
+		return ""
+	}
+	return message.Context.System.Application.ApplicationID
+}
+
+// RawBodyFromContext function returns the raw body of the request carried by
+// ctx, or nil
+func RawBodyFromContext(ctx context.Context) []byte {
+	if rc, ok := ctx.Value(requestContextKey{}).(*requestContext); ok {
+		return rc.body
+	}
+	return nil
+}
+
+// contextReader stops reading once the context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
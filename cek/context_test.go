@@ -0,0 +1,65 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestRequestContext(t *testing.T) {
+	var handled bool
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			handled = true
+			if got := cek.RequestFromContext(ctx); got != message {
+				t.Errorf("RequestFromContext: %v; want %v", got, message)
+			}
+			if got := cek.ApplicationIDFromContext(ctx); got != "com.yourdomain.extension.pizzabot" {
+				t.Errorf("ApplicationIDFromContext: %s", got)
+			}
+			if got := string(cek.RawBodyFromContext(ctx)); got != testRequestBodies[1] {
+				t.Errorf("RawBodyFromContext: %s", got)
+			}
+			return cek.NewResponseBuilder().Build(), nil
+		})))
+	w := httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1])))
+	if !handled || w.Code != http.StatusOK {
+		t.Errorf("Status: %d, handled: %v", w.Code, handled)
+	}
+
+	ctx := context.Background()
+	if cek.RequestFromContext(ctx) != nil || cek.ApplicationIDFromContext(ctx) != "" || cek.RawBodyFromContext(ctx) != nil {
+		t.Error("empty context carries a request")
+	}
+}
+
+func TestParseRequestCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot", cek.WithDebugMode)
+	r := httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[2])).WithContext(ctx)
+	if _, err := ext.ParseRequest(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseRequest: %v; want %v", err, context.Canceled)
+	}
+}
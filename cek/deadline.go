@@ -0,0 +1,84 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrResponseDeadline is returned when the handler does not finish within the
+// response deadline
+var ErrResponseDeadline = errors.New("response deadline exceeded")
+
+// ErrHandlerPanic is returned when the handler panics while it runs under a
+// response deadline, where net/http cannot recover the panic
+var ErrHandlerPanic = errors.New("handler panic")
+
+// WithResponseDeadline function limits the time given to the handler. When
+// the deadline passes, the context of the handler is canceled and fallback is
+// sent instead. With a nil fallback, the request fails with
+// ErrResponseDeadline.
+func WithResponseDeadline(deadline time.Duration, fallback *ResponseMessage) ExtensionOption {
+	return func(ext *Extension) {
+		ext.deadline = deadline
+		ext.fallback = fallback
+	}
+}
+
+type handlerResult struct {
+	response *ResponseMessage
+	err      error
+}
+
+func (e *Extension) serveWithDeadline(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	if e.deadline <= 0 {
+		return e.handler.ServeCEK(ctx, message)
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.deadline)
+	defer cancel()
+
+	done := make(chan handlerResult, 1)
+	go func() {
+		var result handlerResult
+		defer func() {
+			if p := recover(); p != nil {
+				result = handlerResult{err: fmt.Errorf("%w: %v", ErrHandlerPanic, p)}
+			}
+			done <- result
+		}()
+		result.response, result.err = e.handler.ServeCEK(ctx, message)
+	}()
+	select {
+	case result := <-done:
+		return result.response, result.err
+	case <-ctx.Done():
+		go func() {
+			result := <-done
+			logger := LoggerFromContext(ctx)
+			if result.err != nil {
+				logger.Warn("late handler result discarded", "error", result.err.Error())
+			} else {
+				logger.Warn("late handler result discarded")
+			}
+		}()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrResponseDeadline
+		}
+		return nil, ctx.Err()
+	}
+}
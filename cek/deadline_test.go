@@ -0,0 +1,122 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestResponseDeadline(t *testing.T) {
+	fallback := cek.NewResponseBuilder().
+		OutputSpeech(cek.NewOutputSpeechBuilder().
+			AddSpeechText("少々お待ちください。もう一度お試しください。", cek.SpeechInfoLangJA).
+			Build()).
+		ShouldEndSession(true).
+		Build()
+	testCases := []struct {
+		delay      time.Duration
+		fallback   *cek.ResponseMessage
+		wantStatus int
+		wantSpeech string
+		wantCancel bool
+	}{
+		{
+			delay:      0,
+			fallback:   fallback,
+			wantStatus: http.StatusOK,
+			wantSpeech: "起動しました",
+		},
+		{
+			delay:      time.Second,
+			fallback:   fallback,
+			wantStatus: http.StatusOK,
+			wantSpeech: "少々お待ちください。もう一度お試しください。",
+			wantCancel: true,
+		},
+		{
+			delay:      time.Second,
+			fallback:   nil,
+			wantStatus: http.StatusInternalServerError,
+			wantCancel: true,
+		},
+	}
+	for i, testCase := range testCases {
+		canceled := make(chan struct{})
+		ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+			cek.WithDebugMode,
+			cek.WithResponseDeadline(20*time.Millisecond, testCase.fallback),
+			cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				select {
+				case <-time.After(testCase.delay):
+				case <-ctx.Done():
+					close(canceled)
+					return nil, ctx.Err()
+				}
+				return cek.NewResponseBuilder().
+					OutputSpeech(cek.NewOutputSpeechBuilder().
+						AddSpeechText("起動しました", cek.SpeechInfoLangJA).
+						Build()).
+					Build(), nil
+			})))
+		w := httptest.NewRecorder()
+		ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[2])))
+		if w.Code != testCase.wantStatus {
+			t.Errorf("Status %d: %d; want %d", i, w.Code, testCase.wantStatus)
+		}
+		if testCase.wantSpeech != "" && !strings.Contains(w.Body.String(), testCase.wantSpeech) {
+			t.Errorf("Body %d: %s; want speech %s", i, w.Body.String(), testCase.wantSpeech)
+		}
+		if testCase.wantCancel {
+			select {
+			case <-canceled:
+			case <-time.After(time.Second):
+				t.Errorf("Handler %d is not canceled", i)
+			}
+		}
+	}
+}
+
+func TestResponseDeadlineHandlerPanic(t *testing.T) {
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithResponseDeadline(time.Second, nil),
+		cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			panic("boom")
+		})))
+	server := httptest.NewServer(ext)
+	defer server.Close()
+
+	res, err := http.Post(server.URL, "application/json", strings.NewReader(testRequestBodies[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Status: %d; want %d", res.StatusCode, http.StatusInternalServerError)
+	}
+
+	_, err = ext.Process(context.Background(), "", []byte(testRequestBodies[2]))
+	if !errors.Is(err, cek.ErrHandlerPanic) || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Process: %v; want %v", err, cek.ErrHandlerPanic)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxBodySize is the size limit of the request bodies unless set with
+// WithMaxBodySize
+const DefaultMaxBodySize = 1 << 20
+
+// ErrRequestTooLarge is returned when the request body exceeds the size limit
+var ErrRequestTooLarge = errors.New("request body too large")
+
+// WithMaxBodySize function sets the size limit of the request bodies. Larger
+// requests are rejected with ErrRequestTooLarge before being decoded.
+func WithMaxBodySize(n int64) ExtensionOption {
+	return func(ext *Extension) {
+		ext.maxBodySize = n
+	}
+}
+
+func (e *Extension) readBody(r *http.Request) ([]byte, error) {
+	return ReadBody(r, e.maxBodySize)
+}
+
+// ReadBody function reads the body of the request until its context is done.
+// Bodies larger than limit bytes, or DefaultMaxBodySize when limit is zero,
+// are rejected with ErrRequestTooLarge. The body is read in a buffer of the
+// size given by the Content-Length header when there is one, so that it is
+// read without copying.
+func ReadBody(r *http.Request, limit int64) ([]byte, error) {
+	if limit == 0 {
+		limit = DefaultMaxBodySize
+	}
+	if r.ContentLength > limit {
+		return nil, fmt.Errorf("%w: %d bytes", ErrRequestTooLarge, r.ContentLength)
+	}
+	size := int64(512)
+	if r.ContentLength > 0 {
+		// one more byte to read the end of the body without growing
+		size = r.ContentLength + 1
+	}
+	body := make([]byte, 0, size)
+	reader := io.LimitReader(&contextReader{ctx: r.Context(), r: r.Body}, limit+1)
+	for {
+		if len(body) == cap(body) {
+			body = append(body, 0)[:len(body)]
+		}
+		n, err := reader.Read(body[len(body):cap(body)])
+		body = body[:len(body)+n]
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrRequestTooLarge, limit)
+	}
+	return body, nil
+}
+
+// scanMembers calls f with the name of each member of the JSON object starting
+// at i in b and the index of its value, and returns the index after the
+// object. f returns the index after the value. b must be valid JSON, as
+// decoded before. The names are slices of b unless they have escapes.
+func scanMembers(b []byte, i int, f func(name []byte, i int) int) int {
+	i++
+	for {
+		i = skipSpace(b, i)
+		switch b[i] {
+		case '}':
+			return i + 1
+		case ',':
+			i = skipSpace(b, i+1)
+		}
+		end, escaped := scanString(b, i)
+		name := b[i+1 : end-1]
+		if escaped {
+			var unquoted string
+			json.Unmarshal(b[i:end], &unquoted)
+			name = []byte(unquoted)
+		}
+		i = skipSpace(b, end)
+		i = f(name, skipSpace(b, i+1))
+	}
+}
+
+func skipSpace(b []byte, i int) int {
+	for i < len(b) {
+		switch b[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// scanString returns the index after the string starting at i, and whether it
+// has escapes
+func scanString(b []byte, i int) (int, bool) {
+	escaped := false
+	for i++; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			escaped = true
+			i++
+		case '"':
+			return i + 1, escaped
+		}
+	}
+	return i, escaped
+}
+
+// skipValue returns the index after the value starting at i
+func skipValue(b []byte, i int) int {
+	depth := 0
+	for i < len(b) {
+		switch b[i] {
+		case '"':
+			i, _ = scanString(b, i)
+			if depth == 0 {
+				return i
+			}
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				return i
+			}
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return i
+}
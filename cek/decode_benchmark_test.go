@@ -0,0 +1,59 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+var benchmarkRequestTypes = []string{"Event", "Intent", "Launch", "SessionEnded"}
+
+func BenchmarkDecodeRequest(b *testing.B) {
+	for i, name := range benchmarkRequestTypes {
+		body := []byte(testRequestBodies[i])
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for n := 0; n < b.N; n++ {
+				message := &cek.RequestMessage{}
+				if err := json.Unmarshal(body, message); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseRequest(b *testing.B) {
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot", cek.WithDebugMode)
+	for i, name := range benchmarkRequestTypes {
+		body := testRequestBodies[i]
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for n := 0; n < b.N; n++ {
+				r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+				if _, err := ext.ParseRequest(r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	body := testRequestBodies[1]
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot", cek.WithDebugMode, cek.WithMaxBodySize(int64(len(body))))
+	if _, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(body))); err != nil {
+		t.Errorf("ParseRequest: %v", err)
+	}
+
+	large := body + " "
+	r := httptest.NewRequest("POST", "/", strings.NewReader(large))
+	if _, err := ext.ParseRequest(r); !errors.Is(err, cek.ErrRequestTooLarge) {
+		t.Errorf("ParseRequest: %v; want %v", err, cek.ErrRequestTooLarge)
+	}
+	// without a content length the body is cut while reading
+	r = httptest.NewRequest("POST", "/", strings.NewReader(large))
+	r.ContentLength = -1
+	if _, err := ext.ParseRequest(r); !errors.Is(err, cek.ErrRequestTooLarge) {
+		t.Errorf("ParseRequest streamed: %v; want %v", err, cek.ErrRequestTooLarge)
+	}
+
+	w := httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(large)))
+	if w.Code != 413 {
+		t.Errorf("Status: %d; want 413", w.Code)
+	}
+}
+
+func TestDecodeRequestMembers(t *testing.T) {
+	body := `{
+  "version": "1.0",
+  "context": {"System": {"user": {"us\u0065rId": "U1"}, "application": {"applicationId": "app"}}},
+  "session": {"new": true, "sessionAttributes": {"a": "}, \"b\": {"}, "sessionId": "s1", "user": {"userId": "U1"}},
+  "request": {
+    "type": "IntentRequest",
+    "RequestId": "r1",
+    "intent": {"name": "Echo", "slots": {"text": {"name": "text", "value": "{\"unknown\": [1, 2]}"}}}
+  }
+}`
+	message := &cek.RequestMessage{}
+	if err := cek.UnmarshalRequestMessage([]byte(body), message); err != nil {
+		t.Fatal(err)
+	}
+	if fields := message.UnknownFields(); len(fields) > 0 {
+		t.Errorf("UnknownFields() = %v", fields)
+	}
+	if message.Context.System.User.UserID != "U1" || message.RequestID() != "r1" {
+		t.Errorf("user %q, request ID %q", message.Context.System.User.UserID, message.RequestID())
+	}
+	if got := message.Session.SessionAttributes["a"]; got != `}, "b": {` {
+		t.Errorf("session attribute %q", got)
+	}
+	want := &cek.Slot{Name: "text", Value: `{"unknown": [1, 2]}`}
+	if got := message.Request.(*cek.IntentRequest).Intent.Slots["text"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("slot %+v; want %+v", got, want)
+	}
+
+	body = strings.Replace(body, `"intent": {`, `"event": {"namespace": "Clova", "name": "X"}, "intent": {`, 1)
+	message = &cek.RequestMessage{}
+	if err := cek.UnmarshalRequestMessage([]byte(body), message); err != nil {
+		t.Fatal(err)
+	}
+	if fields := message.UnknownFields(); !reflect.DeepEqual(fields, []string{"request.event"}) {
+		t.Errorf("UnknownFields() = %v", fields)
+	}
+}
@@ -14,6 +14,8 @@
 
 package cek
 
+import "time"
+
 func SetPublicKeyStr(key string) func() {
 	var tmp string
 	tmp, publicKeyStr = publicKeyStr, key
@@ -21,3 +23,11 @@ func SetPublicKeyStr(key string) func() {
 		publicKeyStr = tmp
 	}
 }
+
+func (c *MemoryReplayCache) SetNow(now func() time.Time) {
+	c.cache.now = now
+}
+
+func (r *CachingTokenResolver) SetNow(now func() time.Time) {
+	r.cache.now = now
+}
@@ -15,16 +15,38 @@
 package cek
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
+	"time"
+)
+
+// Errors returned by ParseRequest
+var (
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrInvalidApplication = errors.New("invalid application")
 )
 
 // Extension type
 type Extension struct {
-	ID        string
-	debugMode bool
+	ID              string
+	debugMode       bool
+	handler         Handler
+	logger          *slog.Logger
+	metrics         MetricsCollector
+	tracer          Tracer
+	deadline        time.Duration
+	fallback        *ResponseMessage
+	strictResponses bool
+	requestChecks   map[RequestCheck]bool
+	timestampSkew   time.Duration
+	replayCache     ReplayCache
+	replayTTL       time.Duration
+	strictDecoding  bool
+	extraMembers    bool
+	maxBodySize     int64
+	codec           Codec
 }
 
 // ExtensionOption type
@@ -46,26 +68,80 @@ func WithDebugMode(ext *Extension) {
 	ext.debugMode = true
 }
 
-// ParseRequest method
+// ParseRequest method. Reading the body stops with the context error when
+// the context of r is done.
 func (e *Extension) ParseRequest(r *http.Request) (*RequestMessage, error) {
-	defer r.Body.Close()
-	body, err := ioutil.ReadAll(r.Body)
+	message, _, err := e.parseRequest(r.Context(), r.Header.Get(SignatureHeader), nil, r)
 	if err != nil {
 		return nil, err
 	}
+	return message, nil
+}
+
+// parseRequest returns the decoded message along with the error when only the
+// application, request or replay checks fail, so that rejected requests can
+// still be reported. The body is read from r unless r is nil.
+func (e *Extension) parseRequest(ctx context.Context, signature string, body []byte, r *http.Request) (message *RequestMessage, _ []byte, err error) {
+	ctx, span := e.startSpan(ctx, SpanParseRequest)
+	defer func() {
+		if message != nil {
+			setMessageAttributes(span, message)
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if r != nil {
+		defer r.Body.Close()
+		if err := e.trace(ctx, SpanReadBody, func() (err error) {
+			body, err = e.readBody(r.WithContext(ctx))
+			return err
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
 	if !e.debugMode {
-		if err := validateSignature(r.Header.Get("SignatureCEK"), body); err != nil {
-			return nil, fmt.Errorf("invalid signature: %s", err.Error())
+		if err := e.trace(ctx, SpanVerifySignature, func() error {
+			return ValidateSignature(signature, body)
+		}); err != nil {
+			return nil, nil, err
 		}
 	}
 
-	message := &RequestMessage{}
-	if err := json.Unmarshal(body, message); err != nil {
-		return nil, err
+	message = &RequestMessage{}
+	if err := e.trace(ctx, SpanDecodeRequest, func() error {
+		if err := e.decodeRequest(body, message); err != nil {
+			return &decodeError{err: err}
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	if e.strictDecoding {
+		e.warnUnknownFields(ctx, message)
+	}
+	if err := e.trace(ctx, SpanCheckApplication, func() error {
+		if message.Context != nil && message.Context.System != nil && message.Context.System.Application != nil &&
+			message.Context.System.Application.ApplicationID == e.ID {
+			return nil
+		}
+		return ErrInvalidApplication
+	}); err != nil {
+		return message, body, err
+	}
+	if len(e.requestChecks) > 0 {
+		if err := e.trace(ctx, SpanValidateRequest, func() error {
+			return e.validateRequest(message)
+		}); err != nil {
+			return message, body, err
+		}
 	}
-	if message.Context != nil && message.Context.System != nil && message.Context.System.Application != nil &&
-		message.Context.System.Application.ApplicationID == e.ID {
-		return message, nil
+	if e.replayCache != nil {
+		err = e.trace(ctx, SpanCheckReplay, func() error {
+			return e.checkReplay(ctx, message, body)
+		})
 	}
-	return nil, fmt.Errorf("invalid application")
+	return message, body, err
 }
@@ -0,0 +1,190 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Handler interface
+type Handler interface {
+	ServeCEK(ctx context.Context, message *RequestMessage) (*ResponseMessage, error)
+}
+
+// HandlerFunc type
+type HandlerFunc func(ctx context.Context, message *RequestMessage) (*ResponseMessage, error)
+
+// ServeCEK method for implementing Handler interface
+func (f HandlerFunc) ServeCEK(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	return f(ctx, message)
+}
+
+// ErrNoHandler is returned when the extension has no handler to dispatch to
+var ErrNoHandler = errors.New("no handler")
+
+// ErrNoResponse is returned when the handler returns neither a response nor an
+// error
+var ErrNoResponse = errors.New("no response")
+
+// WithHandler function
+func WithHandler(h Handler) ExtensionOption {
+	return func(ext *Extension) {
+		ext.handler = h
+	}
+}
+
+// ProcessError type is returned by Extension.Process. Class is the class of
+// the error in the request logs, and tells whether the request was rejected.
+type ProcessError struct {
+	Class ErrorClass
+	Err   error
+}
+
+// Error method for implementing error interface
+func (e *ProcessError) Error() string {
+	return string(e.Class) + ": " + e.Err.Error()
+}
+
+// Unwrap method
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+// Rejected method reports whether the request itself was invalid, like a bad
+// signature or body, rather than the extension failing to answer it
+func (e *ProcessError) Rejected() bool {
+	return e.Class.rejected() || e.Class == ErrorClassCanceled
+}
+
+// ServeHTTP method parses the request, dispatches it to the handler and
+// writes the response message.
+func (e *Extension) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, span := e.startSpan(r.Context(), SpanServeHTTP)
+	err := e.serve(ctx, span, r.Header.Get(SignatureHeader), nil, r, func(body []byte) error {
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		_, err := w.Write(body)
+		return err
+	})
+	var processError *ProcessError
+	if !errors.As(err, &processError) {
+		return
+	}
+	status := http.StatusInternalServerError
+	switch {
+	case processError.Class == ErrorClassTooLarge:
+		status = http.StatusRequestEntityTooLarge
+	case processError.Rejected():
+		status = http.StatusBadRequest
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// Process method verifies the signature of the request body, decodes it,
+// dispatches it to the handler and returns the encoded response message, like
+// ServeHTTP does for HTTP. It serves the requests coming through other
+// transports, like message queues. The errors are *ProcessError.
+func (e *Extension) Process(ctx context.Context, signature string, body []byte) ([]byte, error) {
+	ctx, span := e.startSpan(ctx, SpanProcess)
+	var response []byte
+	err := e.serve(ctx, span, signature, body, nil, func(body []byte) error {
+		response = body
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// serve processes a request within the span, which it ends, and records it in
+// the logs and metrics. The body is read from r unless r is nil, and the
+// encoded response is passed to write. Only the errors before write are
+// returned.
+func (e *Extension) serve(ctx context.Context, span Span, signature string, body []byte, r *http.Request, write func(body []byte) error) error {
+	rec := &requestRecord{start: time.Now()}
+	defer func() {
+		latency := time.Since(rec.start)
+		e.logRequest(ctx, rec, latency)
+		e.observeRequest(rec, latency)
+		if rec.message != nil {
+			setMessageAttributes(span, rec.message)
+		}
+		if rec.err != nil {
+			span.RecordError(rec.err)
+		}
+		span.End()
+	}()
+	fail := func(class ErrorClass, err error) error {
+		rec.fail(class, err)
+		return &ProcessError{Class: class, Err: err}
+	}
+
+	message, body, err := e.parseRequest(ctx, signature, body, r)
+	rec.message = message
+	if err != nil {
+		return fail(parseErrorClass(err), err)
+	}
+	ctx = withLogger(NewRequestContext(ctx, message, body), e.requestLogger(message))
+
+	response, err := e.dispatch(ctx, message)
+	if errors.Is(err, ErrResponseDeadline) && e.fallback != nil {
+		rec.fail(ErrorClassDeadline, err)
+		response, err = e.fallback, nil
+	}
+	if err != nil {
+		return fail(ErrorClassHandler, err)
+	}
+	if e.strictResponses && rec.errorClass != ErrorClassDeadline {
+		if violations := response.Validate(); violations != nil {
+			return fail(ErrorClassInvalidResponse, fmt.Errorf("%w: %w", ErrInvalidResponse, violations))
+		}
+	}
+
+	if err := e.trace(ctx, SpanEncodeResponse, func() (err error) {
+		body, err = e.getCodec().Marshal(response)
+		return err
+	}); err != nil {
+		return fail(ErrorClassEncode, err)
+	}
+	rec.responseSize = len(body)
+	if err := write(body); err != nil {
+		rec.fail(ErrorClassWrite, err)
+	}
+	return nil
+}
+
+func (e *Extension) dispatch(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	ctx, span := e.startSpan(ctx, SpanHandle)
+	defer span.End()
+	setMessageAttributes(span, message)
+
+	if e.handler == nil {
+		span.RecordError(ErrNoHandler)
+		return nil, ErrNoHandler
+	}
+	response, err := e.serveWithDeadline(ctx, message)
+	if err == nil && response == nil {
+		err = ErrNoResponse
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestServeHTTP(t *testing.T) {
+	response := cek.NewResponseBuilder().
+		OutputSpeech(cek.NewOutputSpeechBuilder().
+			AddSpeechText("起動しました", cek.SpeechInfoLangJA).
+			Build()).
+		Build()
+	testCases := []struct {
+		handler    cek.Handler
+		body       string
+		wantStatus int
+	}{
+		{
+			handler: cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				return response, nil
+			}),
+			body:       testRequestBodies[2],
+			wantStatus: http.StatusOK,
+		},
+		{
+			handler: cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				return nil, errors.New("handler error")
+			}),
+			body:       testRequestBodies[2],
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			handler:    nil,
+			body:       testRequestBodies[2],
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			handler: cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				return nil, nil
+			}),
+			body:       testRequestBodies[2],
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			handler: cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				return response, nil
+			}),
+			body:       `{"request":{"type":"UnknownRequest"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for i, testCase := range testCases {
+		ext := cek.NewExtension("com.yourdomain.extension.pizzabot", cek.WithDebugMode, cek.WithHandler(testCase.handler))
+		w := httptest.NewRecorder()
+		ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testCase.body)))
+		if w.Code != testCase.wantStatus {
+			t.Errorf("Status %d: %d; want %d", i, w.Code, testCase.wantStatus)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		got := map[string]interface{}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]interface{}{}
+		if err := json.Unmarshal(b, &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Response %d: %v; want %v", i, got, want)
+		}
+	}
+}
+
+func TestNoResponse(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		options := []cek.ExtensionOption{
+			cek.WithDebugMode,
+			cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				return nil, nil
+			})),
+		}
+		if strict {
+			options = append(options, cek.WithStrictResponses)
+		}
+		ext := cek.NewExtension("com.yourdomain.extension.pizzabot", options...)
+		_, err := ext.Process(context.Background(), "", []byte(testRequestBodies[2]))
+		var processError *cek.ProcessError
+		if !errors.As(err, &processError) || !errors.Is(err, cek.ErrNoResponse) || processError.Class != cek.ErrorClassHandler {
+			t.Errorf("Process (strict %v): %v; want %v", strict, err, cek.ErrNoResponse)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ErrorClass type
+type ErrorClass string
+
+// ErrorClass constants
+const (
+	ErrorClassNone            ErrorClass = ""
+	ErrorClassCanceled        ErrorClass = "canceled"
+	ErrorClassRead            ErrorClass = "read"
+	ErrorClassTooLarge        ErrorClass = "too_large"
+	ErrorClassSignature       ErrorClass = "signature"
+	ErrorClassDecode          ErrorClass = "decode"
+	ErrorClassApplication     ErrorClass = "application"
+	ErrorClassValidation      ErrorClass = "validation"
+	ErrorClassReplay          ErrorClass = "replay"
+	ErrorClassReplayCache     ErrorClass = "replay_cache"
+	ErrorClassHandler         ErrorClass = "handler"
+	ErrorClassDeadline        ErrorClass = "deadline"
+	ErrorClassInvalidResponse ErrorClass = "invalid_response"
+	ErrorClassEncode          ErrorClass = "encode"
+	ErrorClassWrite           ErrorClass = "write"
+)
+
+// Outcomes reported in request logs
+const (
+	outcomeOK       = "ok"
+	outcomeRejected = "rejected"
+	outcomeFallback = "fallback"
+	outcomeError    = "error"
+)
+
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+type loggerContextKey struct{}
+
+// WithLogger function sets the logger which receives one record per request
+// served by ServeHTTP.
+func WithLogger(logger *slog.Logger) ExtensionOption {
+	return func(ext *Extension) {
+		ext.logger = logger
+	}
+}
+
+// LoggerFromContext function returns the request scoped logger passed to
+// handlers. The logger carries the correlation attributes of the request. A
+// logger discarding all records is returned when the extension has none.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return discardLogger
+}
+
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+func (e *Extension) requestLogger(message *RequestMessage) *slog.Logger {
+	if e.logger == nil {
+		return discardLogger
+	}
+	return e.logger.With(requestAttrs(message)...)
+}
+
+type requestRecord struct {
+	start        time.Time
+	message      *RequestMessage
+	errorClass   ErrorClass
+	err          error
+	responseSize int
+}
+
+func (rec *requestRecord) fail(class ErrorClass, err error) {
+	rec.errorClass = class
+	rec.err = err
+}
+
+func (rec *requestRecord) outcome() string {
+	switch {
+	case rec.errorClass == ErrorClassNone:
+		return outcomeOK
+	case rec.errorClass.rejected():
+		return outcomeRejected
+	case rec.errorClass == ErrorClassDeadline:
+		return outcomeFallback
+	default:
+		return outcomeError
+	}
+}
+
+// rejected reports whether the class is of invalid requests
+func (c ErrorClass) rejected() bool {
+	switch c {
+	case ErrorClassRead, ErrorClassTooLarge, ErrorClassSignature, ErrorClassDecode, ErrorClassApplication, ErrorClassValidation, ErrorClassReplay:
+		return true
+	}
+	return false
+}
+
+func (e *Extension) logRequest(ctx context.Context, rec *requestRecord, latency time.Duration) {
+	if e.logger == nil {
+		return
+	}
+	level := slog.LevelInfo
+	switch rec.outcome() {
+	case outcomeRejected, outcomeFallback:
+		level = slog.LevelWarn
+	case outcomeError:
+		level = slog.LevelError
+	}
+	attrs := []slog.Attr{
+		slog.Duration("latency", latency),
+		slog.String("outcome", rec.outcome()),
+	}
+	if rec.err != nil {
+		attrs = append(attrs,
+			slog.String("error_class", string(rec.errorClass)),
+			slog.String("error", rec.err.Error()))
+	}
+	logger := e.logger
+	if rec.message != nil {
+		logger = logger.With(requestAttrs(rec.message)...)
+	}
+	logger.LogAttrs(ctx, level, "cek request", attrs...)
+}
+
+func requestAttrs(message *RequestMessage) []any {
+	var attrs []any
+	switch request := message.Request.(type) {
+	case *EventRequest:
+		attrs = append(attrs, slog.String("request_type", string(RequestTypeEvent)))
+		if request.Event != nil {
+			attrs = append(attrs, slog.String("event", request.Event.Namespace+"."+request.Event.Name))
+		}
+	case *IntentRequest:
+		attrs = append(attrs, slog.String("request_type", string(RequestTypeIntent)))
+		if request.Intent != nil {
+			attrs = append(attrs, slog.String("intent", request.Intent.Name))
+		}
+	case *LaunchRequest:
+		attrs = append(attrs, slog.String("request_type", string(RequestTypeLaunch)))
+	case *SessionEndedRequest:
+		attrs = append(attrs, slog.String("request_type", string(RequestTypeSessionEnded)))
+	}
+	if requestID := message.RequestID(); requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if message.Context != nil && message.Context.System != nil {
+		if message.Context.System.Application != nil {
+			attrs = append(attrs, slog.String("application_id", message.Context.System.Application.ApplicationID))
+		}
+		if message.Context.System.User != nil {
+			attrs = append(attrs, slog.String("user_hash", hashUserID(message.Context.System.User.UserID)))
+		}
+	}
+	if message.Session != nil {
+		attrs = append(attrs, slog.String("session_id", message.Session.SessionID))
+	}
+	return attrs
+}
+
+// hashUserID keeps user IDs out of logs while still allowing records of the
+// same user to be correlated.
+func hashUserID(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:8])
+}
+
+func parseErrorClass(err error) ErrorClass {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var decodeErr *decodeError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassCanceled
+	case errors.Is(err, ErrRequestTooLarge):
+		return ErrorClassTooLarge
+	case errors.Is(err, ErrInvalidSignature):
+		return ErrorClassSignature
+	case errors.Is(err, ErrInvalidApplication):
+		return ErrorClassApplication
+	case errors.Is(err, ErrInvalidRequest):
+		return ErrorClassValidation
+	case errors.Is(err, ErrReplayCache):
+		return ErrorClassReplayCache
+	case errors.Is(err, ErrReplayedRequest):
+		return ErrorClassReplay
+	case errors.Is(err, ErrInvalidRequestType), errors.As(err, &syntaxError), errors.As(err, &typeError),
+		errors.As(err, &decodeErr):
+		return ErrorClassDecode
+	default:
+		return ErrorClassRead
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestLogging(t *testing.T) {
+	testCases := []struct {
+		body      string
+		handler   cek.HandlerFunc
+		wantAttrs map[string]interface{}
+	}{
+		{
+			body: testRequestBodies[0],
+			handler: func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				cek.LoggerFromContext(ctx).Info("handled")
+				return cek.NewResponseBuilder().Build(), nil
+			},
+			wantAttrs: map[string]interface{}{
+				"level":          "INFO",
+				"request_type":   "EventRequest",
+				"event":          "ClovaSkill.SkillEnabled",
+				"request_id":     "f09874hiudf-sdf-4wku-flksdjfo4hjsdf",
+				"application_id": "com.yourdomain.extension.pizzabot",
+				"session_id":     "a29cfead-c5ba-474d-8745-6c1a6625f0c5",
+				"outcome":        "ok",
+			},
+		},
+		{
+			body: testRequestBodies[1],
+			handler: func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				cek.LoggerFromContext(ctx).Info("handled")
+				return nil, errors.New("backend unavailable")
+			},
+			wantAttrs: map[string]interface{}{
+				"level":        "ERROR",
+				"request_type": "IntentRequest",
+				"intent":       "OrderPizza",
+				"outcome":      "error",
+				"error_class":  "handler",
+				"error":        "backend unavailable",
+			},
+		},
+		{
+			body: strings.Replace(testRequestBodies[2], "com.yourdomain.extension.pizzabot", "com.example.other", -1),
+			wantAttrs: map[string]interface{}{
+				"level":        "WARN",
+				"request_type": "LaunchRequest",
+				"outcome":      "rejected",
+				"error_class":  "application",
+			},
+		},
+	}
+	for i, testCase := range testCases {
+		buf := &bytes.Buffer{}
+		ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+			cek.WithDebugMode,
+			cek.WithHandler(testCase.handler),
+			cek.WithLogger(slog.New(slog.NewJSONHandler(buf, nil))))
+		ext.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(testCase.body)))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		record := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+			t.Fatal(err)
+		}
+		for key, want := range testCase.wantAttrs {
+			if record[key] != want {
+				t.Errorf("Record %d %s: %v; want %v", i, key, record[key], want)
+			}
+		}
+		if _, ok := record["latency"]; !ok {
+			t.Errorf("Record %d: latency is missing", i)
+		}
+		if hash, _ := record["user_hash"].(string); hash == "" || strings.Contains(buf.String(), "U399a1e08a8d474521fc4bbd8c7b4148f") {
+			t.Errorf("Record %d: user ID is not hashed: %s", i, buf.String())
+		}
+		if testCase.handler != nil {
+			handled := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(lines[0]), &handled); err != nil {
+				t.Fatal(err)
+			}
+			if handled["msg"] != "handled" || handled["session_id"] != "a29cfead-c5ba-474d-8745-6c1a6625f0c5" {
+				t.Errorf("Record %d: handler record %v has no correlation attributes", i, handled)
+			}
+		}
+	}
+}
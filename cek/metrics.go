@@ -0,0 +1,262 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestStats type
+type RequestStats struct {
+	// RequestType is empty when the request could not be decoded
+	RequestType RequestType
+	// Name is the intent name of an IntentRequest or the
+	// "namespace.name" of an EventRequest
+	Name         string
+	ErrorClass   ErrorClass
+	Duration     time.Duration
+	ResponseSize int
+}
+
+// MetricsCollector interface
+type MetricsCollector interface {
+	ObserveRequest(stats *RequestStats)
+}
+
+// WithMetricsCollector function
+func WithMetricsCollector(c MetricsCollector) ExtensionOption {
+	return func(ext *Extension) {
+		ext.metrics = c
+	}
+}
+
+func (e *Extension) observeRequest(rec *requestRecord, latency time.Duration) {
+	if e.metrics == nil {
+		return
+	}
+	stats := &RequestStats{
+		ErrorClass:   rec.errorClass,
+		Duration:     latency,
+		ResponseSize: rec.responseSize,
+	}
+	if rec.message != nil {
+		stats.RequestType, stats.Name = requestTypeAndName(rec.message.Request)
+	}
+	e.metrics.ObserveRequest(stats)
+}
+
+func requestTypeAndName(request Request) (RequestType, string) {
+	switch request := request.(type) {
+	case *EventRequest:
+		if request.Event == nil {
+			return RequestTypeEvent, ""
+		}
+		return RequestTypeEvent, request.Event.Namespace + "." + request.Event.Name
+	case *IntentRequest:
+		if request.Intent == nil {
+			return RequestTypeIntent, ""
+		}
+		return RequestTypeIntent, request.Intent.Name
+	case *LaunchRequest:
+		return RequestTypeLaunch, ""
+	case *SessionEndedRequest:
+		return RequestTypeSessionEnded, ""
+	}
+	return "", ""
+}
+
+// Default histogram buckets of PrometheusCollector
+var (
+	DefaultLatencyBuckets      = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	DefaultResponseSizeBuckets = []float64{256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
+)
+
+// PrometheusCollector type is a MetricsCollector exposing the collected
+// metrics in the Prometheus text exposition format.
+type PrometheusCollector struct {
+	mu                  sync.Mutex
+	requests            map[requestKey]uint64
+	signatureFailures   uint64
+	applicationMismatch uint64
+	handlerErrors       uint64
+	latency             map[RequestType]*histogram
+	responseSize        map[RequestType]*histogram
+	latencyBuckets      []float64
+	responseSizeBuckets []float64
+}
+
+type requestKey struct {
+	requestType RequestType
+	name        string
+	errorClass  ErrorClass
+}
+
+// NewPrometheusCollector function
+func NewPrometheusCollector() *PrometheusCollector {
+	return &PrometheusCollector{
+		requests:            map[requestKey]uint64{},
+		latency:             map[RequestType]*histogram{},
+		responseSize:        map[RequestType]*histogram{},
+		latencyBuckets:      DefaultLatencyBuckets,
+		responseSizeBuckets: DefaultResponseSizeBuckets,
+	}
+}
+
+// ObserveRequest method for implementing MetricsCollector interface
+func (c *PrometheusCollector) ObserveRequest(stats *RequestStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests[requestKey{stats.RequestType, stats.Name, stats.ErrorClass}]++
+	switch stats.ErrorClass {
+	case ErrorClassSignature:
+		c.signatureFailures++
+	case ErrorClassApplication:
+		c.applicationMismatch++
+	case ErrorClassHandler:
+		c.handlerErrors++
+	}
+
+	latency, ok := c.latency[stats.RequestType]
+	if !ok {
+		latency = newHistogram(c.latencyBuckets)
+		c.latency[stats.RequestType] = latency
+	}
+	latency.observe(stats.Duration.Seconds())
+
+	if stats.ErrorClass == ErrorClassNone {
+		size, ok := c.responseSize[stats.RequestType]
+		if !ok {
+			size = newHistogram(c.responseSizeBuckets)
+			c.responseSize[stats.RequestType] = size
+		}
+		size.observe(float64(stats.ResponseSize))
+	}
+}
+
+// ServeHTTP method writes the metrics in the Prometheus text format
+func (c *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	c.write(bw)
+	bw.Flush()
+}
+
+func (c *PrometheusCollector) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, "cek_requests_total", "counter", "Total number of CEK requests.")
+	keys := make([]requestKey, 0, len(c.requests))
+	for key := range c.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].requestType != keys[j].requestType {
+			return keys[i].requestType < keys[j].requestType
+		}
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].errorClass < keys[j].errorClass
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "cek_requests_total{type=%s,name=%s,error_class=%s} %d\n",
+			quoteLabel(typeLabel(key.requestType)), quoteLabel(key.name), quoteLabel(string(key.errorClass)), c.requests[key])
+	}
+
+	writeHeader(w, "cek_signature_failures_total", "counter", "Total number of requests with an invalid signature.")
+	fmt.Fprintf(w, "cek_signature_failures_total %d\n", c.signatureFailures)
+	writeHeader(w, "cek_application_mismatches_total", "counter", "Total number of requests for another application.")
+	fmt.Fprintf(w, "cek_application_mismatches_total %d\n", c.applicationMismatch)
+	writeHeader(w, "cek_handler_errors_total", "counter", "Total number of requests the handler failed to serve.")
+	fmt.Fprintf(w, "cek_handler_errors_total %d\n", c.handlerErrors)
+
+	writeHeader(w, "cek_request_duration_seconds", "histogram", "Latency of CEK requests.")
+	writeHistograms(w, "cek_request_duration_seconds", c.latency)
+	writeHeader(w, "cek_response_size_bytes", "histogram", "Size of CEK response bodies.")
+	writeHistograms(w, "cek_response_size_bytes", c.responseSize)
+}
+
+func writeHeader(w *bufio.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeHistograms(w *bufio.Writer, name string, histograms map[RequestType]*histogram) {
+	types := make([]RequestType, 0, len(histograms))
+	for t := range histograms {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	for _, t := range types {
+		h := histograms[t]
+		label := quoteLabel(typeLabel(t))
+		for i, bound := range h.bounds {
+			fmt.Fprintf(w, "%s_bucket{type=%s,le=\"%s\"} %d\n", name, label, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{type=%s,le=\"+Inf\"} %d\n", name, label, h.count)
+		fmt.Fprintf(w, "%s_sum{type=%s} %s\n", name, label, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{type=%s} %d\n", name, label, h.count)
+	}
+}
+
+func typeLabel(t RequestType) string {
+	if t == "" {
+		return "unknown"
+	}
+	return string(t)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// histogram keeps cumulative bucket counts
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	collector := cek.NewPrometheusCollector()
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithMetricsCollector(collector),
+		cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			if _, ok := message.Request.(*cek.IntentRequest); ok {
+				return nil, errors.New("handler error")
+			}
+			return cek.NewResponseBuilder().Build(), nil
+		})))
+	bodies := []string{
+		testRequestBodies[0],
+		testRequestBodies[1],
+		testRequestBodies[2],
+		testRequestBodies[2],
+		strings.Replace(testRequestBodies[3], "com.yourdomain.extension.pizzabot", "com.example.other", -1),
+		`{"request":`,
+	}
+	for _, body := range bodies {
+		ext.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	}
+
+	w := httptest.NewRecorder()
+	collector.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	got := w.Body.String()
+	for _, want := range []string{
+		"# TYPE cek_requests_total counter\n",
+		`cek_requests_total{type="EventRequest",name="ClovaSkill.SkillEnabled",error_class=""} 1` + "\n",
+		`cek_requests_total{type="IntentRequest",name="OrderPizza",error_class="handler"} 1` + "\n",
+		`cek_requests_total{type="LaunchRequest",name="",error_class=""} 2` + "\n",
+		`cek_requests_total{type="SessionEndedRequest",name="",error_class="application"} 1` + "\n",
+		`cek_requests_total{type="unknown",name="",error_class="decode"} 1` + "\n",
+		"cek_signature_failures_total 0\n",
+		"cek_application_mismatches_total 1\n",
+		"cek_handler_errors_total 1\n",
+		"# TYPE cek_request_duration_seconds histogram\n",
+		`cek_request_duration_seconds_bucket{type="LaunchRequest",le="+Inf"} 2` + "\n",
+		`cek_request_duration_seconds_count{type="LaunchRequest"} 2` + "\n",
+		`cek_response_size_bytes_bucket{type="LaunchRequest",le="256"} 2` + "\n",
+		`cek_response_size_bytes_count{type="LaunchRequest"} 2` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Metrics do not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `cek_response_size_bytes_count{type="IntentRequest"}`) {
+		t.Errorf("Response size is observed for a failed request:\n%s", got)
+	}
+}
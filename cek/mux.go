@@ -0,0 +1,106 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"sync"
+)
+
+// ServeMux type dispatches requests to the handler registered for the intent
+// name, the event or the request type. Requests without a registered handler
+// go to NotFound, and fail with ErrNoHandler when it is nil.
+type ServeMux struct {
+	NotFound Handler
+
+	mu           sync.RWMutex
+	intents      map[string]Handler
+	events       map[string]Handler
+	launch       Handler
+	sessionEnded Handler
+}
+
+// NewServeMux function
+func NewServeMux() *ServeMux {
+	return &ServeMux{
+		intents: map[string]Handler{},
+		events:  map[string]Handler{},
+	}
+}
+
+// HandleIntent method
+func (m *ServeMux) HandleIntent(name string, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.intents[name] = h
+}
+
+// HandleEvent method registers h for the event. An empty name registers h
+// for all events of the namespace without a handler of their own.
+func (m *ServeMux) HandleEvent(namespace, name string, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events[namespace+"."+name] = h
+}
+
+// HandleLaunch method
+func (m *ServeMux) HandleLaunch(h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.launch = h
+}
+
+// HandleSessionEnded method
+func (m *ServeMux) HandleSessionEnded(h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessionEnded = h
+}
+
+// Handler method returns the handler for the request, or nil
+func (m *ServeMux) Handler(message *RequestMessage) Handler {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	switch request := message.Request.(type) {
+	case *IntentRequest:
+		if request.Intent != nil {
+			return m.intents[request.Intent.Name]
+		}
+	case *EventRequest:
+		if request.Event != nil {
+			if h, ok := m.events[request.Event.Namespace+"."+request.Event.Name]; ok {
+				return h
+			}
+			return m.events[request.Event.Namespace+"."]
+		}
+	case *LaunchRequest:
+		return m.launch
+	case *SessionEndedRequest:
+		return m.sessionEnded
+	}
+	return nil
+}
+
+// ServeCEK method for implementing Handler interface
+func (m *ServeMux) ServeCEK(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	h := m.Handler(message)
+	if h == nil {
+		h = m.NotFound
+	}
+	if h == nil {
+		return nil, ErrNoHandler
+	}
+	return h.ServeCEK(ctx, message)
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func namedHandler(name string) cek.Handler {
+	return cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+		return cek.NewResponseBuilder().SessionAttributes(map[string]string{"handler": name}).Build(), nil
+	})
+}
+
+func TestServeMux(t *testing.T) {
+	mux := cek.NewServeMux()
+	mux.HandleIntent("OrderPizza", namedHandler("order"))
+	mux.HandleEvent("ClovaSkill", "SkillEnabled", namedHandler("enabled"))
+	mux.HandleEvent("AudioPlayer", "", namedHandler("audio"))
+	mux.HandleLaunch(namedHandler("launch"))
+
+	testCases := []struct {
+		request     cek.Request
+		notFound    cek.Handler
+		wantHandler string
+		wantErr     error
+	}{
+		{request: &cek.IntentRequest{Intent: &cek.Intent{Name: "OrderPizza"}}, wantHandler: "order"},
+		{request: &cek.EventRequest{Event: &cek.Event{Namespace: "ClovaSkill", Name: "SkillEnabled"}}, wantHandler: "enabled"},
+		{request: &cek.EventRequest{Event: &cek.Event{Namespace: "AudioPlayer", Name: "PlayStarted"}}, wantHandler: "audio"},
+		{request: &cek.LaunchRequest{}, wantHandler: "launch"},
+		{request: &cek.SessionEndedRequest{}, wantErr: cek.ErrNoHandler},
+		{request: &cek.IntentRequest{Intent: &cek.Intent{Name: "CancelPizza"}}, wantErr: cek.ErrNoHandler},
+		{request: &cek.IntentRequest{Intent: &cek.Intent{Name: "CancelPizza"}}, notFound: namedHandler("not found"), wantHandler: "not found"},
+	}
+	for i, testCase := range testCases {
+		mux.NotFound = testCase.notFound
+		response, err := mux.ServeCEK(context.Background(), &cek.RequestMessage{Request: testCase.request})
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("Error %d: %v; want %v", i, err, testCase.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := response.SessionAttributes["handler"]; got != testCase.wantHandler {
+			t.Errorf("Handler %d: %s; want %s", i, got, testCase.wantHandler)
+		}
+	}
+}
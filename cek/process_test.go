@@ -0,0 +1,107 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestProcess(t *testing.T) {
+	testPublicKey, err := ioutil.ReadFile(filepath.Join("testdata", "public.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cek.SetPublicKeyStr(string(testPublicKey))()
+
+	body := []byte(testRequestBodies[2])
+	b, err := generateSignature(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(b)
+
+	handlerErr := errors.New("handler error")
+	var failing bool
+	var requestBody []byte
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			if failing {
+				return nil, handlerErr
+			}
+			requestBody = cek.RawBodyFromContext(ctx)
+			return cek.NewResponseBuilder().
+				OutputSpeech(cek.NewOutputSpeechBuilder().AddSpeechText("起動しました", cek.SpeechInfoLangJA).Build()).
+				Build(), nil
+		})))
+
+	response, err := ext.Process(context.Background(), signature, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(requestBody) != string(body) {
+		t.Errorf("RawBodyFromContext() = %s", requestBody)
+	}
+	message := &cek.ResponseMessage{}
+	if err := json.Unmarshal(response, message); err != nil {
+		t.Fatal(err)
+	}
+	if speech, ok := message.Response.OutputSpeech.Values.(*cek.SpeechInfo); !ok || speech.Value != "起動しました" {
+		t.Errorf("response %s", response)
+	}
+
+	testCases := []struct {
+		signature    string
+		failing      bool
+		wantClass    cek.ErrorClass
+		wantErr      error
+		wantRejected bool
+	}{
+		{
+			signature:    "invalidsignature",
+			wantClass:    cek.ErrorClassSignature,
+			wantErr:      cek.ErrInvalidSignature,
+			wantRejected: true,
+		},
+		{
+			signature: signature,
+			failing:   true,
+			wantClass: cek.ErrorClassHandler,
+			wantErr:   handlerErr,
+		},
+	}
+	for i, testCase := range testCases {
+		failing = testCase.failing
+		response, err := ext.Process(context.Background(), testCase.signature, body)
+		var processError *cek.ProcessError
+		if !errors.As(err, &processError) || !errors.Is(err, testCase.wantErr) {
+			t.Errorf("Process %d: %v; want %v", i, err, testCase.wantErr)
+			continue
+		}
+		if processError.Class != testCase.wantClass || processError.Rejected() != testCase.wantRejected {
+			t.Errorf("Process %d: class %s, rejected %v", i, processError.Class, processError.Rejected())
+		}
+		if response != nil {
+			t.Errorf("Process %d: response %s", i, response)
+		}
+	}
+}
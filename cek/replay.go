@@ -0,0 +1,108 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Errors returned by ParseRequest with replay protection
+var (
+	ErrReplayedRequest = errors.New("replayed request")
+	ErrReplayCache     = errors.New("replay cache failed")
+)
+
+// ReplayCache interface holds the keys of recently seen requests. It can be
+// backed by a shared store, like Redis with SET NX, so that several instances
+// of an extension reject the same replays.
+type ReplayCache interface {
+	// Add records key for ttl and reports whether it was not recorded yet.
+	// It must be atomic.
+	Add(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// WithReplayProtection function makes ParseRequest reject requests seen
+// within ttl with ErrReplayedRequest. Requests are keyed by their request ID,
+// or by the hash of the body when they have none. A non-positive ttl is
+// DefaultTimestampSkew, the window in which the timestamps are accepted.
+func WithReplayProtection(cache ReplayCache, ttl time.Duration) ExtensionOption {
+	if ttl <= 0 {
+		ttl = DefaultTimestampSkew
+	}
+	return func(ext *Extension) {
+		ext.replayCache = cache
+		ext.replayTTL = ttl
+	}
+}
+
+func (e *Extension) checkReplay(ctx context.Context, message *RequestMessage, body []byte) error {
+	added, err := e.replayCache.Add(ctx, e.replayKey(message, body), e.replayTTL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrReplayCache, err)
+	}
+	if !added {
+		return ErrReplayedRequest
+	}
+	return nil
+}
+
+// replayKey is scoped by the extension ID so that extensions can share a
+// cache
+func (e *Extension) replayKey(message *RequestMessage, body []byte) string {
+	if requestID := message.RequestID(); requestID != "" {
+		return e.ID + ":request:" + requestID
+	}
+	sum := sha256.Sum256(body)
+	return e.ID + ":body:" + hex.EncodeToString(sum[:])
+}
+
+// MemoryReplayCache type is a ReplayCache in memory holding at most a number
+// of keys. The oldest keys are dropped first when it is full, so the capacity
+// should exceed the number of requests expected within the TTL.
+type MemoryReplayCache struct {
+	mu    sync.Mutex
+	cache *ttlCache
+}
+
+// NewMemoryReplayCache function
+func NewMemoryReplayCache(capacity int) *MemoryReplayCache {
+	return &MemoryReplayCache{
+		cache: newTTLCache(capacity),
+	}
+}
+
+// Add method for implementing ReplayCache interface
+func (c *MemoryReplayCache) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.cache.get(key); ok {
+		return false, nil
+	}
+	c.cache.add(key, struct{}{}, c.cache.now().Add(ttl))
+	return true, nil
+}
+
+// Len method returns the number of keys held
+func (c *MemoryReplayCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.len()
+}
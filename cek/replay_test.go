@@ -0,0 +1,125 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestMemoryReplayCache(t *testing.T) {
+	now := time.Date(2018, 6, 11, 9, 19, 23, 0, time.UTC)
+	cache := cek.NewMemoryReplayCache(2)
+	cache.SetNow(func() time.Time { return now })
+	ctx := context.Background()
+	add := func(key string, want bool) {
+		t.Helper()
+		if added, err := cache.Add(ctx, key, time.Minute); err != nil || added != want {
+			t.Errorf("Add(%s): %v, %v; want %v", key, added, err, want)
+		}
+	}
+
+	add("a", true)
+	add("a", false)
+	now = now.Add(30 * time.Second)
+	add("b", true)
+	add("c", true)
+	// the capacity drops a, the oldest key
+	add("a", true)
+	if cache.Len() != 2 {
+		t.Errorf("Len: %d; want 2", cache.Len())
+	}
+	now = now.Add(time.Minute)
+	add("c", true)
+	if cache.Len() != 1 {
+		t.Errorf("Len after expiry: %d; want 1", cache.Len())
+	}
+}
+
+type failingReplayCache struct{}
+
+func (failingReplayCache) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return false, errors.New("unavailable")
+}
+
+func TestReplayProtection(t *testing.T) {
+	cache := cek.NewMemoryReplayCache(100)
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithReplayProtection(cache, time.Minute))
+	parse := func(body string) error {
+		_, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		return err
+	}
+
+	for i, body := range testRequestBodies {
+		if err := parse(body); err != nil {
+			t.Errorf("ParseRequest %d: %v", i, err)
+		}
+		if err := parse(body); !errors.Is(err, cek.ErrReplayedRequest) {
+			t.Errorf("ParseRequest replay %d: %v; want %v", i, err, cek.ErrReplayedRequest)
+		}
+	}
+	// requests are keyed by ID, so a different body with the same ID is a replay
+	event := strings.Replace(testRequestBodies[0], "2018-06-11T09:19:23Z", "2018-06-11T09:19:24Z", 1)
+	if err := parse(event); !errors.Is(err, cek.ErrReplayedRequest) {
+		t.Errorf("ParseRequest same ID: %v; want %v", err, cek.ErrReplayedRequest)
+	}
+	launch := strings.Replace(testRequestBodies[2], `"type": "LaunchRequest"`, `"type": "LaunchRequest", "requestId": "launch-1"`, 1)
+	if err := parse(launch); err != nil {
+		t.Errorf("ParseRequest launch: %v", err)
+	}
+	if err := parse(strings.Replace(launch, `"new": false`, `"new": true`, 1)); !errors.Is(err, cek.ErrReplayedRequest) {
+		t.Errorf("ParseRequest same launch ID: %v; want %v", err, cek.ErrReplayedRequest)
+	}
+
+	w := httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1])))
+	if w.Code != 400 {
+		t.Errorf("Status: %d; want 400", w.Code)
+	}
+
+	ext = cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithReplayProtection(failingReplayCache{}, time.Minute))
+	if err := parse(testRequestBodies[1]); !errors.Is(err, cek.ErrReplayCache) {
+		t.Errorf("ParseRequest: %v; want %v", err, cek.ErrReplayCache)
+	}
+	_, err := ext.Process(context.Background(), "", []byte(testRequestBodies[1]))
+	var processError *cek.ProcessError
+	if !errors.As(err, &processError) || processError.Class != cek.ErrorClassReplayCache || processError.Rejected() {
+		t.Errorf("Process: %v; want a %s error not rejected", err, cek.ErrorClassReplayCache)
+	}
+	w = httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1])))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("ServeHTTP status with a failing cache: %d; want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	ext = cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithReplayProtection(cek.NewMemoryReplayCache(100), 0))
+	parse(testRequestBodies[1])
+	if err := parse(testRequestBodies[1]); !errors.Is(err, cek.ErrReplayedRequest) {
+		t.Errorf("ParseRequest replay without TTL: %v; want %v", err, cek.ErrReplayedRequest)
+	}
+}
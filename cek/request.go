@@ -17,6 +17,7 @@ package cek
 import (
 	"encoding/json"
 	"errors"
+	"time"
 )
 
 // PlayerActivity type
@@ -76,111 +77,240 @@ const (
 	SlotValueTypeDateTimeInterval SlotValueType = "DATETIME.INTERVAL"
 )
 
+// ErrInvalidRequestType is returned when the request type is not known
+var ErrInvalidRequestType = errors.New("invalid request type")
+
 // RequestMessage type
 type RequestMessage struct {
-	Context *Context `json:"context"`
-	Request Request  `json:"request"`
-	Session *Session `json:"session"`
-	Version string   `json:"version"`
+	Context *Context                   `json:"context"`
+	Request Request                    `json:"request"`
+	Session *Session                   `json:"session"`
+	Version string                     `json:"version"`
+	Extra   map[string]json.RawMessage `json:"-"`
 }
 
-type rawRequest struct {
-	Type    RequestType `json:"type"`
-	Request Request     `json:"-"`
+// requestFields has the members of all the request types, so that the message
+// is decoded with a single call of json.Unmarshal whatever its request type
+type requestFields struct {
+	Type      RequestType `json:"type"`
+	RequestID string      `json:"requestId"`
+	Timestamp string      `json:"timestamp"`
+	Event     *Event      `json:"event"`
+	Intent    *Intent     `json:"intent"`
 }
 
-func (r *rawRequest) UnmarshalJSON(b []byte) error {
-	type alias rawRequest
-	raw := alias{}
-	if err := json.Unmarshal(b, &raw); err != nil {
-		return err
-	}
-	var request Request
-	switch raw.Type {
+func (f *requestFields) request() (Request, error) {
+	switch f.Type {
 	case RequestTypeEvent:
-		request = &EventRequest{}
+		return &EventRequest{Event: f.Event, RequestID: f.RequestID, Timestamp: f.Timestamp}, nil
 	case RequestTypeIntent:
-		request = &IntentRequest{}
+		return &IntentRequest{Intent: f.Intent, RequestID: f.RequestID, Timestamp: f.Timestamp}, nil
 	case RequestTypeLaunch:
-		request = &LaunchRequest{}
+		return &LaunchRequest{RequestID: f.RequestID, Timestamp: f.Timestamp}, nil
 	case RequestTypeSessionEnded:
-		request = &SessionEndedRequest{}
-	default:
-		return errors.New("invalid request type")
-	}
-	if err := json.Unmarshal(b, request); err != nil {
-		return err
+		return &SessionEndedRequest{RequestID: f.RequestID, Timestamp: f.Timestamp}, nil
 	}
-	r.Request = request
-	return nil
+	return nil, ErrInvalidRequestType
 }
 
-// UnmarshalJSON method for RequestMessage
-func (m *RequestMessage) UnmarshalJSON(b []byte) error {
+// MarshalJSON method for RequestMessage. The request is written with its type,
+// and the members in Extra are written as well.
+func (m RequestMessage) MarshalJSON() ([]byte, error) {
 	type alias RequestMessage
-	raw := struct {
-		Request rawRequest `json:"request"`
+	request, err := marshalRequest(m.Request)
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtra(&struct {
+		Request json.RawMessage `json:"request"`
 		*alias
 	}{
-		alias: (*alias)(m),
+		Request: request,
+		alias:   (*alias)(&m),
+	}, m.Extra)
+}
+
+func marshalRequest(request Request) (json.RawMessage, error) {
+	if request == nil {
+		return json.RawMessage("null"), nil
+	}
+	requestType := RequestTypeOf(request)
+	if requestType == "" {
+		return nil, ErrInvalidRequestType
+	}
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
 	}
-	if err := json.Unmarshal(b, &raw); err != nil {
+	typed := `{"type":"` + string(requestType) + `"`
+	if len(b) > 2 {
+		typed += ","
+	}
+	return append([]byte(typed), b[1:]...), nil
+}
+
+// UnmarshalJSON method for RequestMessage. The message is decoded by a single
+// call of json.Unmarshal, as the nested types have no UnmarshalJSON method. The
+// unknown members are not kept; UnmarshalRequestMessage keeps them.
+func (m *RequestMessage) UnmarshalJSON(b []byte) error {
+	fields := &requestMessageFields{}
+	if err := json.Unmarshal(b, fields); err != nil {
 		return err
 	}
-	m.Request = raw.Request.Request
+	return fields.message(m)
+}
+
+// requestMessageFields has the members of RequestMessage with the request in
+// requestFields. It has no UnmarshalJSON method, so that it is decoded by the
+// codec of the extension rather than by encoding/json.
+type requestMessageFields struct {
+	Context *Context       `json:"context"`
+	Request *requestFields `json:"request"`
+	Session *Session       `json:"session"`
+	Version string         `json:"version"`
+}
+
+func (f *requestMessageFields) message(m *RequestMessage) error {
+	m.Context, m.Request, m.Session, m.Version = f.Context, nil, f.Session, f.Version
+	if f.Request != nil {
+		request, err := f.Request.request()
+		if err != nil {
+			return err
+		}
+		m.Request = request
+	}
 	return nil
 }
 
 // Context type
 type Context struct {
-	AudioPlayer *AudioPlayer `json:"AudioPlayer,omitempty"`
-	System      *System      `json:"System"`
+	AudioPlayer *AudioPlayer               `json:"AudioPlayer,omitempty"`
+	System      *System                    `json:"System"`
+	Extra       map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Context. The members in Extra are written as well.
+func (c Context) MarshalJSON() ([]byte, error) {
+	type alias Context
+	return marshalExtra((*alias)(&c), c.Extra)
 }
 
 // AudioPlayer type
 type AudioPlayer struct {
-	OffsetInMilliseconds int            `json:"offsetInMilliseconds,omitempty"`
-	PlayerActivity       PlayerActivity `json:"playerActivity"`
-	Stream               interface{}    `json:"stream,omitempty"`
-	TotalInMilliseconds  int            `json:"totalInMilliseconds,omitempty"`
+	OffsetInMilliseconds int                        `json:"offsetInMilliseconds,omitempty"`
+	PlayerActivity       PlayerActivity             `json:"playerActivity"`
+	Stream               *AudioStream               `json:"stream,omitempty"`
+	TotalInMilliseconds  int                        `json:"totalInMilliseconds,omitempty"`
+	Extra                map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for AudioPlayer. The members in Extra are written as well.
+func (p AudioPlayer) MarshalJSON() ([]byte, error) {
+	type alias AudioPlayer
+	return marshalExtra((*alias)(&p), p.Extra)
+}
+
+// IsPlaying method
+func (p *AudioPlayer) IsPlaying() bool {
+	return p != nil && p.PlayerActivity == PlayerActivityPLAYING
+}
+
+// CurrentToken method returns the token of the stream on the device, or an
+// empty string
+func (p *AudioPlayer) CurrentToken() string {
+	if p == nil || p.Stream == nil {
+		return ""
+	}
+	return p.Stream.Token
+}
+
+// RemainingDuration method returns the time left to play in the stream. It is
+// zero when the total length is not reported.
+func (p *AudioPlayer) RemainingDuration() time.Duration {
+	if p == nil || p.TotalInMilliseconds <= p.OffsetInMilliseconds {
+		return 0
+	}
+	return time.Duration(p.TotalInMilliseconds-p.OffsetInMilliseconds) * time.Millisecond
 }
 
 // System type
 type System struct {
-	Application *Application `json:"application"`
-	Device      *Device      `json:"device"`
-	User        *User        `json:"user"`
+	Application *Application               `json:"application"`
+	Device      *Device                    `json:"device"`
+	User        *User                      `json:"user"`
+	Extra       map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for System. The members in Extra are written as well.
+func (s System) MarshalJSON() ([]byte, error) {
+	type alias System
+	return marshalExtra((*alias)(&s), s.Extra)
 }
 
 // Application type
 type Application struct {
-	ApplicationID string `json:"applicationId"`
+	ApplicationID string                     `json:"applicationId"`
+	Extra         map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Application. The members in Extra are written as well.
+func (a Application) MarshalJSON() ([]byte, error) {
+	type alias Application
+	return marshalExtra((*alias)(&a), a.Extra)
 }
 
 // Device type
 type Device struct {
-	DeviceID string   `json:"deviceId"`
-	Display  *Display `json:"display"`
+	DeviceID string                     `json:"deviceId"`
+	Display  *Display                   `json:"display"`
+	Extra    map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Device. The members in Extra are written as well.
+func (d Device) MarshalJSON() ([]byte, error) {
+	type alias Device
+	return marshalExtra((*alias)(&d), d.Extra)
 }
 
 // Display type
 type Display struct {
-	ContentLayer *ContentLayer `json:"contentLayer,omitempty"`
-	DPI          int           `json:"dpi,omitempty"`
-	Orientation  Orientation   `json:"orientation,omitempty"`
-	Size         Size          `json:"size"`
+	ContentLayer *ContentLayer              `json:"contentLayer,omitempty"`
+	DPI          int                        `json:"dpi,omitempty"`
+	Orientation  Orientation                `json:"orientation,omitempty"`
+	Size         Size                       `json:"size"`
+	Extra        map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Display. The members in Extra are written as well.
+func (d Display) MarshalJSON() ([]byte, error) {
+	type alias Display
+	return marshalExtra((*alias)(&d), d.Extra)
 }
 
 // ContentLayer type
 type ContentLayer struct {
-	Width  int `json:"width"`
-	Height int `json:"height"`
+	Width  int                        `json:"width"`
+	Height int                        `json:"height"`
+	Extra  map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for ContentLayer. The members in Extra are written as well.
+func (c ContentLayer) MarshalJSON() ([]byte, error) {
+	type alias ContentLayer
+	return marshalExtra((*alias)(&c), c.Extra)
 }
 
 // User type
 type User struct {
-	UserID      string `json:"userId"`
-	AccessToken string `json:"accessToken,omitempty"`
+	UserID      string                     `json:"userId"`
+	AccessToken string                     `json:"accessToken,omitempty"`
+	Extra       map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for User. The members in Extra are written as well.
+func (u User) MarshalJSON() ([]byte, error) {
+	type alias User
+	return marshalExtra((*alias)(&u), u.Extra)
 }
 
 // Request interface
@@ -188,45 +318,146 @@ type Request interface {
 	Request()
 }
 
+// RequestTypeOf function returns the type of the request, or an empty string
+// when the request is not one of this package
+func RequestTypeOf(request Request) RequestType {
+	switch request.(type) {
+	case *EventRequest:
+		return RequestTypeEvent
+	case *IntentRequest:
+		return RequestTypeIntent
+	case *LaunchRequest:
+		return RequestTypeLaunch
+	case *SessionEndedRequest:
+		return RequestTypeSessionEnded
+	}
+	return ""
+}
+
+// RequestID method returns the ID of the request, or an empty string
+func (m *RequestMessage) RequestID() string {
+	switch request := m.Request.(type) {
+	case *EventRequest:
+		return request.RequestID
+	case *IntentRequest:
+		return request.RequestID
+	case *LaunchRequest:
+		return request.RequestID
+	case *SessionEndedRequest:
+		return request.RequestID
+	}
+	return ""
+}
+
+// Timestamp method returns the timestamp of the request, or an empty string
+func (m *RequestMessage) Timestamp() string {
+	switch request := m.Request.(type) {
+	case *EventRequest:
+		return request.Timestamp
+	case *IntentRequest:
+		return request.Timestamp
+	case *LaunchRequest:
+		return request.Timestamp
+	case *SessionEndedRequest:
+		return request.Timestamp
+	}
+	return ""
+}
+
 // EventRequest type
 type EventRequest struct {
-	Event     *Event `json:"event"`
-	RequestID string `json:"requestId"`
-	Timestamp string `json:"timestamp"`
+	Event     *Event                     `json:"event"`
+	RequestID string                     `json:"requestId"`
+	Timestamp string                     `json:"timestamp"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for EventRequest. The members in Extra are written as well.
+func (r EventRequest) MarshalJSON() ([]byte, error) {
+	type alias EventRequest
+	return marshalExtra((*alias)(&r), r.Extra)
 }
 
 // Event type
 type Event struct {
-	Name      string      `json:"name"`
-	Namespace string      `json:"namespace"`
-	Payload   interface{} `json:"payload"`
+	Name      string                     `json:"name"`
+	Namespace string                     `json:"namespace"`
+	Payload   interface{}                `json:"payload"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Event. The members in Extra are written as well.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type alias Event
+	return marshalExtra((*alias)(&e), e.Extra)
 }
 
 // IntentRequest type
 type IntentRequest struct {
-	Intent *Intent `json:"intent"`
+	Intent    *Intent                    `json:"intent"`
+	RequestID string                     `json:"requestId,omitempty"`
+	Timestamp string                     `json:"timestamp,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for IntentRequest. The members in Extra are written as well.
+func (r IntentRequest) MarshalJSON() ([]byte, error) {
+	type alias IntentRequest
+	return marshalExtra((*alias)(&r), r.Extra)
 }
 
 // Intent type
 type Intent struct {
-	Name  string           `json:"name"`
-	Slots map[string]*Slot `json:"slots"`
+	Name  string                     `json:"name"`
+	Slots map[string]*Slot           `json:"slots"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Intent. The members in Extra are written as well.
+func (i Intent) MarshalJSON() ([]byte, error) {
+	type alias Intent
+	return marshalExtra((*alias)(&i), i.Extra)
 }
 
 // Slot type
 type Slot struct {
-	Name      string        `json:"name"`
-	Value     string        `json:"value"`
-	ValueType SlotValueType `json:"valueType,omitempty"`
-	Unit      string        `json:"unit,omitempty"`
+	Name      string                     `json:"name"`
+	Value     string                     `json:"value"`
+	ValueType SlotValueType              `json:"valueType,omitempty"`
+	Unit      string                     `json:"unit,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Slot. The members in Extra are written as well.
+func (s Slot) MarshalJSON() ([]byte, error) {
+	type alias Slot
+	return marshalExtra((*alias)(&s), s.Extra)
 }
 
 // LaunchRequest type
 type LaunchRequest struct {
+	RequestID string                     `json:"requestId,omitempty"`
+	Timestamp string                     `json:"timestamp,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for LaunchRequest. The members in Extra are written as well.
+func (r LaunchRequest) MarshalJSON() ([]byte, error) {
+	type alias LaunchRequest
+	return marshalExtra((*alias)(&r), r.Extra)
 }
 
 // SessionEndedRequest type
 type SessionEndedRequest struct {
+	RequestID string                     `json:"requestId,omitempty"`
+	Timestamp string                     `json:"timestamp,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for SessionEndedRequest. The members in Extra are written as well.
+func (r SessionEndedRequest) MarshalJSON() ([]byte, error) {
+	type alias SessionEndedRequest
+	return marshalExtra((*alias)(&r), r.Extra)
 }
 
 // Request method for implementing Request interface
@@ -243,8 +474,15 @@ func (*SessionEndedRequest) Request() {}
 
 // Session type
 type Session struct {
-	New               bool              `json:"new"`
-	SessionAttributes map[string]string `json:"sessionAttributes"`
-	SessionID         string            `json:"sessionId"`
-	User              *User             `json:"user"`
+	New               bool                       `json:"new"`
+	SessionAttributes map[string]string          `json:"sessionAttributes"`
+	SessionID         string                     `json:"sessionId"`
+	User              *User                      `json:"user"`
+	Extra             map[string]json.RawMessage `json:"-"`
+}
+
+// MarshalJSON method for Session. The members in Extra are written as well.
+func (s Session) MarshalJSON() ([]byte, error) {
+	type alias Session
+	return marshalExtra((*alias)(&s), s.Extra)
 }
@@ -0,0 +1,158 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestAudioPlayerContext(t *testing.T) {
+	body := `{
+  "AudioPlayer": {
+    "offsetInMilliseconds": 60000,
+    "totalInMilliseconds": 180000,
+    "playerActivity": "PLAYING",
+    "stream": {
+      "beginAtInMilliseconds": 0,
+      "progressReport": {
+        "progressReportDelayInMilliseconds": 10000,
+        "progressReportIntervalInMilliseconds": 60000
+      },
+      "token": "eJyr5lIqSSwqVrJSUE",
+      "url": "https://DUMMY_DOMAIN/song.mp3",
+      "urlPlayable": true
+    }
+  },
+  "System": {}
+}`
+	ctx := &cek.Context{}
+	if err := json.Unmarshal([]byte(body), ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := &cek.AudioStream{
+		ProgressReport: &cek.ProgressReport{
+			ProgressReportDelayInMilliseconds:    10000,
+			ProgressReportIntervalInMilliseconds: 60000,
+		},
+		Token:       "eJyr5lIqSSwqVrJSUE",
+		URL:         "https://DUMMY_DOMAIN/song.mp3",
+		URLPlayable: true,
+	}
+	if !reflect.DeepEqual(ctx.AudioPlayer.Stream, want) {
+		t.Errorf("Stream %v; want %v", ctx.AudioPlayer.Stream, want)
+	}
+
+	testCases := []struct {
+		player        *cek.AudioPlayer
+		wantPlaying   bool
+		wantToken     string
+		wantRemaining time.Duration
+	}{
+		{
+			player:        ctx.AudioPlayer,
+			wantPlaying:   true,
+			wantToken:     "eJyr5lIqSSwqVrJSUE",
+			wantRemaining: 2 * time.Minute,
+		},
+		{
+			player: &cek.AudioPlayer{
+				OffsetInMilliseconds: 1000,
+				PlayerActivity:       cek.PlayerActivityIDLE,
+			},
+		},
+		{
+			player: nil,
+		},
+	}
+	for i, testCase := range testCases {
+		if got := testCase.player.IsPlaying(); got != testCase.wantPlaying {
+			t.Errorf("IsPlaying %d: %v", i, got)
+		}
+		if got := testCase.player.CurrentToken(); got != testCase.wantToken {
+			t.Errorf("CurrentToken %d: %s", i, got)
+		}
+		if got := testCase.player.RemainingDuration(); got != testCase.wantRemaining {
+			t.Errorf("RemainingDuration %d: %v", i, got)
+		}
+	}
+}
+
+func TestRequestMessageMarshalJSON(t *testing.T) {
+	wantTypes := []cek.RequestType{cek.RequestTypeEvent, cek.RequestTypeIntent, cek.RequestTypeLaunch, cek.RequestTypeSessionEnded}
+	for i, body := range testRequestBodies {
+		message := &cek.RequestMessage{}
+		if err := json.Unmarshal([]byte(body), message); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(message)
+		if err != nil {
+			t.Fatalf("Marshal %d: %v", i, err)
+		}
+		var raw struct {
+			Request struct {
+				Type cek.RequestType `json:"type"`
+			} `json:"request"`
+		}
+		if err := json.Unmarshal(b, &raw); err != nil {
+			t.Fatal(err)
+		}
+		if raw.Request.Type != wantTypes[i] {
+			t.Errorf("type %d: %q; want %q", i, raw.Request.Type, wantTypes[i])
+		}
+		got := &cek.RequestMessage{}
+		if err := json.Unmarshal(b, got); err != nil {
+			t.Fatalf("Unmarshal %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, message) {
+			t.Errorf("message %d: decoded %s", i, b)
+		}
+	}
+
+	if _, err := json.Marshal(&cek.RequestMessage{Request: otherRequest{}}); !errors.Is(err, cek.ErrInvalidRequestType) {
+		t.Errorf("Marshal unknown request: %v; want %v", err, cek.ErrInvalidRequestType)
+	}
+}
+
+type otherRequest struct{}
+
+func (otherRequest) Request() {}
+
+func TestRequestMessageRequestID(t *testing.T) {
+	body := `{
+  "version": "1.0",
+  "request": {
+    "type": "IntentRequest",
+    "requestId": "intent-1",
+    "timestamp": "2018-06-11T09:19:23Z",
+    "intent": {"name": "Clova.YesIntent", "slots": null}
+  }
+}`
+	message := &cek.RequestMessage{}
+	if err := json.Unmarshal([]byte(body), message); err != nil {
+		t.Fatal(err)
+	}
+	if message.RequestID() != "intent-1" || message.Timestamp() != "2018-06-11T09:19:23Z" {
+		t.Errorf("RequestID() = %q, Timestamp() = %q", message.RequestID(), message.Timestamp())
+	}
+	if got := cek.RequestTypeOf(message.Request); got != cek.RequestTypeIntent {
+		t.Errorf("RequestTypeOf() = %q", got)
+	}
+}
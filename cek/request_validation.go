@@ -0,0 +1,215 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RequestCheck type
+type RequestCheck string
+
+// RequestCheck constants
+const (
+	// RequestCheckVersion checks that the version of the message is supported
+	RequestCheckVersion RequestCheck = "version"
+	// RequestCheckTimestamp checks that the timestamp of the request, when it
+	// has one, is within the skew window of the current time
+	RequestCheckTimestamp RequestCheck = "timestamp"
+	// RequestCheckUser checks that the users of the session and of the context
+	// are the same
+	RequestCheckUser RequestCheck = "user"
+	// RequestCheckRequiredFields checks that the fields needed by the request
+	// type are present
+	RequestCheckRequiredFields RequestCheck = "required_fields"
+)
+
+// DefaultTimestampSkew is the skew window of RequestCheckTimestamp
+const DefaultTimestampSkew = 150 * time.Second
+
+// SupportedVersions are the message versions accepted by RequestCheckVersion
+var SupportedVersions = []string{"1.0"}
+
+// ErrInvalidRequest is wrapped by the errors of the request checks
+var ErrInvalidRequest = errors.New("invalid request")
+
+// RequestValidationError type is returned by ParseRequest when a request
+// check fails
+type RequestValidationError struct {
+	Check   RequestCheck
+	Message string
+}
+
+// Error method for implementing error interface
+func (e *RequestValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidRequest.Error(), e.Check, e.Message)
+}
+
+// Unwrap method returns ErrInvalidRequest
+func (e *RequestValidationError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// WithRequestChecks function enables the checks run by ParseRequest after the
+// application check. All checks are enabled when none is given. Requests
+// failing a check are answered with 400 Bad Request by ServeHTTP.
+func WithRequestChecks(checks ...RequestCheck) ExtensionOption {
+	if len(checks) == 0 {
+		checks = []RequestCheck{RequestCheckVersion, RequestCheckTimestamp, RequestCheckUser, RequestCheckRequiredFields}
+	}
+	return func(ext *Extension) {
+		if ext.requestChecks == nil {
+			ext.requestChecks = map[RequestCheck]bool{}
+		}
+		for _, check := range checks {
+			ext.requestChecks[check] = true
+		}
+	}
+}
+
+// WithTimestampSkew function sets the skew window of RequestCheckTimestamp
+func WithTimestampSkew(d time.Duration) ExtensionOption {
+	return func(ext *Extension) {
+		ext.timestampSkew = d
+	}
+}
+
+// Normalize method replaces the nil session attributes and slots with empty
+// maps, and fills the slot names from the keys of the slots
+func (m *RequestMessage) Normalize() {
+	if m.Session != nil && m.Session.SessionAttributes == nil {
+		m.Session.SessionAttributes = map[string]string{}
+	}
+	if request, ok := m.Request.(*IntentRequest); ok && request.Intent != nil {
+		if request.Intent.Slots == nil {
+			request.Intent.Slots = map[string]*Slot{}
+		}
+		for name, slot := range request.Intent.Slots {
+			if slot != nil && slot.Name == "" {
+				slot.Name = name
+			}
+		}
+	}
+}
+
+// validateRequest runs the enabled checks, the required fields first, and
+// returns the first failure. The message is normalized when it passes.
+func (e *Extension) validateRequest(message *RequestMessage) error {
+	if e.requestChecks[RequestCheckRequiredFields] {
+		if err := checkRequiredFields(message); err != nil {
+			return err
+		}
+	}
+	if e.requestChecks[RequestCheckVersion] && !isSupportedVersion(message.Version) {
+		return &RequestValidationError{
+			Check:   RequestCheckVersion,
+			Message: fmt.Sprintf("unsupported version %q", message.Version),
+		}
+	}
+	if e.requestChecks[RequestCheckTimestamp] {
+		if err := e.checkTimestamp(message); err != nil {
+			return err
+		}
+	}
+	if e.requestChecks[RequestCheckUser] {
+		if err := checkUser(message); err != nil {
+			return err
+		}
+	}
+	message.Normalize()
+	return nil
+}
+
+func isSupportedVersion(version string) bool {
+	for _, supported := range SupportedVersions {
+		if version == supported {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Extension) checkTimestamp(message *RequestMessage) error {
+	value := message.Timestamp()
+	if value == "" {
+		return nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return &RequestValidationError{
+			Check:   RequestCheckTimestamp,
+			Message: fmt.Sprintf("invalid timestamp %q", value),
+		}
+	}
+	skew := e.timestampSkew
+	if skew == 0 {
+		skew = DefaultTimestampSkew
+	}
+	if d := time.Since(timestamp); d > skew || d < -skew {
+		return &RequestValidationError{
+			Check:   RequestCheckTimestamp,
+			Message: fmt.Sprintf("timestamp %s is not within %s", value, skew),
+		}
+	}
+	return nil
+}
+
+func checkUser(message *RequestMessage) error {
+	if message.Session == nil || message.Session.User == nil ||
+		message.Context == nil || message.Context.System == nil || message.Context.System.User == nil {
+		return nil
+	}
+	if message.Session.User.UserID != message.Context.System.User.UserID {
+		return &RequestValidationError{
+			Check:   RequestCheckUser,
+			Message: "session user and context user differ",
+		}
+	}
+	return nil
+}
+
+func checkRequiredFields(message *RequestMessage) error {
+	missing := func(field string) error {
+		return &RequestValidationError{
+			Check:   RequestCheckRequiredFields,
+			Message: "missing " + field,
+		}
+	}
+	if message.Context.System.User == nil || message.Context.System.User.UserID == "" {
+		return missing("context.System.user.userId")
+	}
+	switch request := message.Request.(type) {
+	case *EventRequest:
+		if request.Event == nil || request.Event.Namespace == "" || request.Event.Name == "" {
+			return missing("request.event")
+		}
+		if request.RequestID == "" {
+			return missing("request.requestId")
+		}
+		return nil
+	case *IntentRequest:
+		if request.Intent == nil || request.Intent.Name == "" {
+			return missing("request.intent.name")
+		}
+	case nil:
+		return missing("request")
+	}
+	if message.Session == nil || message.Session.SessionID == "" {
+		return missing("session.sessionId")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestRequestChecks(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	old := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	event := strings.Replace(testRequestBodies[0], "2018-06-11T09:19:23Z", now, 1)
+	testCases := []struct {
+		checks    []cek.RequestCheck
+		body      string
+		wantCheck cek.RequestCheck
+	}{
+		{
+			body: event,
+		},
+		{
+			body: testRequestBodies[1],
+		},
+		{
+			body:      strings.Replace(testRequestBodies[1], `"version": "1.0"`, `"version": "2.0"`, 1),
+			wantCheck: cek.RequestCheckVersion,
+		},
+		{
+			checks: []cek.RequestCheck{cek.RequestCheckTimestamp},
+			body:   strings.Replace(testRequestBodies[1], `"version": "1.0"`, `"version": "2.0"`, 1),
+		},
+		{
+			body:      strings.Replace(event, now, old, 1),
+			wantCheck: cek.RequestCheckTimestamp,
+		},
+		{
+			checks: []cek.RequestCheck{cek.RequestCheckVersion},
+			body:   strings.Replace(event, now, old, 1),
+		},
+		{
+			body: strings.Replace(testRequestBodies[2], `"type": "LaunchRequest"`,
+				`"type": "LaunchRequest", "requestId": "launch-1", "timestamp": "`+old+`"`, 1),
+			wantCheck: cek.RequestCheckTimestamp,
+		},
+		{
+			body:      strings.Replace(testRequestBodies[2], `"userId": "U399a1e08a8d474521fc4bbd8c7b4148f"`, `"userId": "Uother"`, 1),
+			wantCheck: cek.RequestCheckUser,
+		},
+		{
+			body:      strings.Replace(testRequestBodies[1], `"name": "OrderPizza"`, `"name": ""`, 1),
+			wantCheck: cek.RequestCheckRequiredFields,
+		},
+		{
+			body:      strings.Replace(event, `"requestId": "f09874hiudf-sdf-4wku-flksdjfo4hjsdf"`, `"requestId": ""`, 1),
+			wantCheck: cek.RequestCheckRequiredFields,
+		},
+	}
+	for i, testCase := range testCases {
+		ext := cek.NewExtension("com.yourdomain.extension.pizzabot", cek.WithDebugMode, cek.WithRequestChecks(testCase.checks...))
+		message, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(testCase.body)))
+		if testCase.wantCheck == "" {
+			if err != nil {
+				t.Errorf("ParseRequest %d: %v", i, err)
+			}
+			continue
+		}
+		var validationError *cek.RequestValidationError
+		if !errors.As(err, &validationError) || !errors.Is(err, cek.ErrInvalidRequest) {
+			t.Errorf("ParseRequest %d: %v; want %T", i, err, validationError)
+			continue
+		}
+		if validationError.Check != testCase.wantCheck {
+			t.Errorf("Check %d: %s; want %s", i, validationError.Check, testCase.wantCheck)
+		}
+		if message != nil {
+			t.Errorf("Message %d: %v; want nil", i, message)
+		}
+	}
+}
+
+func TestRequestChecksServeHTTP(t *testing.T) {
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithRequestChecks(cek.RequestCheckTimestamp),
+		cek.WithTimestampSkew(time.Minute))
+	w := httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[0])))
+	if w.Code != 400 {
+		t.Errorf("Status: %d; want 400", w.Code)
+	}
+}
+
+func TestRequestMessageNormalize(t *testing.T) {
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot", cek.WithDebugMode, cek.WithRequestChecks(cek.RequestCheckUser))
+	message, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(
+		strings.Replace(testRequestBodies[2], `"sessionAttributes": {}`, `"sessionAttributes": null`, 1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Session.SessionAttributes == nil {
+		t.Error("SessionAttributes is nil")
+	}
+
+	message = &cek.RequestMessage{
+		Request: &cek.IntentRequest{Intent: &cek.Intent{
+			Name:  "OrderPizza",
+			Slots: map[string]*cek.Slot{"pizzaType": {Value: "ペパロニ"}},
+		}},
+	}
+	message.Normalize()
+	if name := message.Request.(*cek.IntentRequest).Intent.Slots["pizzaType"].Name; name != "pizzaType" {
+		t.Errorf("Slot name: %s; want pizzaType", name)
+	}
+}
@@ -14,6 +14,11 @@
 
 package cek
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 // OutputSpeechType type
 type OutputSpeechType string
 
@@ -62,6 +67,7 @@ type ResponseMessage struct {
 
 // Response type
 type Response struct {
+	// Card is a content template, like *ImageTextCard
 	Card             interface{}   `json:"card"`
 	Directives       []*Directive  `json:"directives"`
 	OutputSpeech     *OutputSpeech `json:"outputSpeech"`
@@ -69,12 +75,78 @@ type Response struct {
 	ShouldEndSession bool          `json:"shouldEndSession"`
 }
 
+// UnmarshalJSON method for implementing json.Unmarshaler interface. The cards
+// of the types this package has are decoded into their structs, like
+// *ImageTextCard for ImageText. Other cards are decoded as into an
+// interface{}.
+func (r *Response) UnmarshalJSON(b []byte) error {
+	type alias Response
+	raw := struct {
+		Card json.RawMessage `json:"card"`
+		*alias
+	}{
+		alias: (*alias)(r),
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	card, err := unmarshalCard(raw.Card)
+	if err != nil {
+		return err
+	}
+	r.Card = card
+	return nil
+}
+
 // Directive type
 type Directive struct {
 	Header  *Header     `json:"header"`
 	Payload interface{} `json:"payload"`
 }
 
+// UnmarshalJSON method for implementing json.Unmarshaler interface. The
+// payloads of the directives this package builds are decoded into their
+// types, like *AudioPlayerPlayPayload for AudioPlayer.Play. Other payloads are
+// decoded as into an interface{}.
+func (d *Directive) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Header  *Header         `json:"header"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	var payload interface{}
+	if len(raw.Payload) > 0 && !bytes.Equal(raw.Payload, []byte("null")) {
+		var typed interface{}
+		if raw.Header != nil {
+			typed = newDirectivePayload(raw.Header.Namespace, raw.Header.Name)
+		}
+		switch p := typed.(type) {
+		case nil:
+			if err := json.Unmarshal(raw.Payload, &payload); err != nil {
+				return err
+			}
+		case *struct{}:
+			// the payload of the directives without one, see NewDirective
+			if err := json.Unmarshal(raw.Payload, p); err != nil {
+				return err
+			}
+			payload = *p
+		default:
+			if err := json.Unmarshal(raw.Payload, p); err != nil {
+				return err
+			}
+			payload = p
+		}
+	}
+	*d = Directive{
+		Header:  raw.Header,
+		Payload: payload,
+	}
+	return nil
+}
+
 // Header type
 type Header struct {
 	MessageID string `json:"messageId"`
@@ -90,11 +162,67 @@ type OutputSpeech struct {
 	Verbose *Verbose         `json:"verbose,omitempty"`
 }
 
+// UnmarshalJSON method for implementing json.Unmarshaler interface
+func (os *OutputSpeech) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Brief   *SpeechInfo      `json:"brief"`
+		Type    OutputSpeechType `json:"type"`
+		Values  json.RawMessage  `json:"values"`
+		Verbose *Verbose         `json:"verbose"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	values, err := unmarshalSpeechInfoValues(string(raw.Type), raw.Values)
+	if err != nil {
+		return err
+	}
+	*os = OutputSpeech{
+		Brief:   raw.Brief,
+		Type:    raw.Type,
+		Values:  values,
+		Verbose: raw.Verbose,
+	}
+	return nil
+}
+
 // SpeechInfoValues type
 type SpeechInfoValues interface {
 	SpeechInfoValues()
 }
 
+// unmarshalSpeechInfoValues decodes the values of a SimpleSpeech into a
+// *SpeechInfo and the values of a SpeechList into a SpeechInfoArray. The values
+// of other types are decoded by their JSON kind.
+func unmarshalSpeechInfoValues(speechType string, b json.RawMessage) (SpeechInfoValues, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return nil, nil
+	}
+	switch speechType {
+	case string(OutputSpeechTypeSimpleSpeech):
+	case string(OutputSpeechTypeSpeechList):
+		return unmarshalSpeechInfoArray(b)
+	default:
+		if b[0] == '[' {
+			return unmarshalSpeechInfoArray(b)
+		}
+	}
+	var value *SpeechInfo
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func unmarshalSpeechInfoArray(b json.RawMessage) (SpeechInfoValues, error) {
+	var values SpeechInfoArray
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
 // SpeechInfo type
 type SpeechInfo struct {
 	Lang  SpeechInfoLang `json:"lang"`
@@ -117,6 +245,26 @@ type Verbose struct {
 	Values SpeechInfoValues        `json:"values"`
 }
 
+// UnmarshalJSON method for implementing json.Unmarshaler interface
+func (v *Verbose) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type   OutputSpeechVerboseType `json:"type"`
+		Values json.RawMessage         `json:"values"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	values, err := unmarshalSpeechInfoValues(string(raw.Type), raw.Values)
+	if err != nil {
+		return err
+	}
+	*v = Verbose{
+		Type:   raw.Type,
+		Values: values,
+	}
+	return nil
+}
+
 // Reprompt type
 type Reprompt struct {
 	OutputSpeech *OutputSpeech `json:"outputSpeech"`
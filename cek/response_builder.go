@@ -86,9 +86,10 @@ func (b *ResponseBuilder) Build() *ResponseMessage {
 
 // OutputSpeechBuilder type
 type OutputSpeechBuilder struct {
-	brief    *SpeechInfo
-	verbose  *Verbose
-	speeches []*SpeechInfo
+	brief           *SpeechInfo
+	verbose         *Verbose
+	verboseSpeeches []*SpeechInfo
+	speeches        []*SpeechInfo
 }
 
 // NewOutputSpeechBuilder function
@@ -98,21 +99,37 @@ func NewOutputSpeechBuilder() *OutputSpeechBuilder {
 
 // AddSpeechText method
 func (b *OutputSpeechBuilder) AddSpeechText(text string, lang SpeechInfoLang) *OutputSpeechBuilder {
-	b.speeches = append(b.speeches, &SpeechInfo{
-		Lang:  lang,
-		Type:  SpeechInfoTypePlainText,
-		Value: text,
-	})
+	b.speeches = append(b.speeches, speechText(text, lang))
 	return b
 }
 
 // AddSpeechURL method
 func (b *OutputSpeechBuilder) AddSpeechURL(url string) *OutputSpeechBuilder {
-	b.speeches = append(b.speeches, &SpeechInfo{
-		Lang:  SpeechInfoLangEmpty,
-		Type:  SpeechInfoTypeURL,
-		Value: url,
-	})
+	b.speeches = append(b.speeches, speechURL(url))
+	return b
+}
+
+// BriefText method sets the brief speech of a SpeechSet
+func (b *OutputSpeechBuilder) BriefText(text string, lang SpeechInfoLang) *OutputSpeechBuilder {
+	b.brief = speechText(text, lang)
+	return b
+}
+
+// BriefURL method sets the brief speech of a SpeechSet
+func (b *OutputSpeechBuilder) BriefURL(url string) *OutputSpeechBuilder {
+	b.brief = speechURL(url)
+	return b
+}
+
+// AddVerboseText method adds a speech to the verbose part of a SpeechSet
+func (b *OutputSpeechBuilder) AddVerboseText(text string, lang SpeechInfoLang) *OutputSpeechBuilder {
+	b.verboseSpeeches = append(b.verboseSpeeches, speechText(text, lang))
+	return b
+}
+
+// AddVerboseURL method adds a speech to the verbose part of a SpeechSet
+func (b *OutputSpeechBuilder) AddVerboseURL(url string) *OutputSpeechBuilder {
+	b.verboseSpeeches = append(b.verboseSpeeches, speechURL(url))
 	return b
 }
 
@@ -123,23 +140,53 @@ func (b *OutputSpeechBuilder) SpeechSet(brief *SpeechInfo, verbose *Verbose) *Ou
 	return b
 }
 
-// Build method
+// Build method returns a SpeechSet when both its brief and verbose parts are
+// set, as the platform requires both. Otherwise it returns a SimpleSpeech for a
+// single speech added with AddSpeechText or AddSpeechURL and a SpeechList for
+// the others, and a brief or verbose part set alone is not used. The verbose
+// part is a SimpleSpeech or a SpeechList the same way.
 func (b *OutputSpeechBuilder) Build() *OutputSpeech {
-	if b.brief != nil && b.verbose != nil {
-		return &OutputSpeech{
-			Brief:   b.brief,
-			Type:    OutputSpeechTypeSpeechSet,
-			Verbose: b.verbose,
+	verbose := b.verbose
+	if len(b.verboseSpeeches) > 0 {
+		verboseType, values := speechValues(b.verboseSpeeches)
+		verbose = &Verbose{
+			Type:   OutputSpeechVerboseType(verboseType),
+			Values: values,
 		}
 	}
-	if len(b.speeches) == 1 {
+	if b.brief != nil && verbose != nil {
 		return &OutputSpeech{
-			Type:   OutputSpeechTypeSimpleSpeech,
-			Values: b.speeches[0],
+			Brief:   b.brief,
+			Type:    OutputSpeechTypeSpeechSet,
+			Verbose: verbose,
 		}
 	}
+	speechType, values := speechValues(b.speeches)
 	return &OutputSpeech{
-		Type:   OutputSpeechTypeSpeechList,
-		Values: SpeechInfoArray(b.speeches),
+		Type:   speechType,
+		Values: values,
+	}
+}
+
+func speechValues(speeches []*SpeechInfo) (OutputSpeechType, SpeechInfoValues) {
+	if len(speeches) == 1 {
+		return OutputSpeechTypeSimpleSpeech, speeches[0]
+	}
+	return OutputSpeechTypeSpeechList, SpeechInfoArray(speeches)
+}
+
+func speechText(text string, lang SpeechInfoLang) *SpeechInfo {
+	return &SpeechInfo{
+		Lang:  lang,
+		Type:  SpeechInfoTypePlainText,
+		Value: text,
+	}
+}
+
+func speechURL(url string) *SpeechInfo {
+	return &SpeechInfo{
+		Lang:  SpeechInfoLangEmpty,
+		Type:  SpeechInfoTypeURL,
+		Value: url,
 	}
 }
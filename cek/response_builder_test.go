@@ -223,3 +223,123 @@ func TestResponseBuilder(t *testing.T) {
 		}
 	}
 }
+
+func TestOutputSpeechBuilderSpeechSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		speech *cek.OutputSpeech
+		want   *cek.OutputSpeech
+	}{
+		{
+			name: "verbose list",
+			speech: cek.NewOutputSpeechBuilder().
+				BriefText("天気予報です。", cek.SpeechInfoLangJA).
+				AddVerboseText("週末まで全国に梅雨…猛暑和らぐ。", cek.SpeechInfoLangJA).
+				AddVerboseURL("https://DUMMY_DOMAIN/weather.mp3").
+				Build(),
+			want: &cek.OutputSpeech{
+				Type:  cek.OutputSpeechTypeSpeechSet,
+				Brief: &cek.SpeechInfo{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "天気予報です。"},
+				Verbose: &cek.Verbose{
+					Type: cek.OutputSpeechVerboseTypeSpeechList,
+					Values: cek.SpeechInfoArray{
+						{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "週末まで全国に梅雨…猛暑和らぐ。"},
+						{Lang: cek.SpeechInfoLangEmpty, Type: cek.SpeechInfoTypeURL, Value: "https://DUMMY_DOMAIN/weather.mp3"},
+					},
+				},
+			},
+		},
+		{
+			name: "verbose simple",
+			speech: cek.NewOutputSpeechBuilder().
+				AddVerboseText("Today is sunny.", cek.SpeechInfoLangEN).
+				BriefURL("https://DUMMY_DOMAIN/chime.mp3").
+				Build(),
+			want: &cek.OutputSpeech{
+				Type:  cek.OutputSpeechTypeSpeechSet,
+				Brief: &cek.SpeechInfo{Lang: cek.SpeechInfoLangEmpty, Type: cek.SpeechInfoTypeURL, Value: "https://DUMMY_DOMAIN/chime.mp3"},
+				Verbose: &cek.Verbose{
+					Type:   cek.OutputSpeechVerboseTypeSimpleSpeech,
+					Values: &cek.SpeechInfo{Lang: cek.SpeechInfoLangEN, Type: cek.SpeechInfoTypePlainText, Value: "Today is sunny."},
+				},
+			},
+		},
+		{
+			name: "brief only",
+			speech: cek.NewOutputSpeechBuilder().
+				SpeechSet(&cek.SpeechInfo{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "天気予報です。"}, nil).
+				AddSpeechText("晴れです。", cek.SpeechInfoLangJA).
+				Build(),
+			want: &cek.OutputSpeech{
+				Type:   cek.OutputSpeechTypeSimpleSpeech,
+				Values: &cek.SpeechInfo{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "晴れです。"},
+			},
+		},
+		{
+			name: "verbose only",
+			speech: cek.NewOutputSpeechBuilder().
+				AddVerboseText("週末まで全国に梅雨…猛暑和らぐ。", cek.SpeechInfoLangJA).
+				AddSpeechText("晴れです。", cek.SpeechInfoLangJA).
+				AddSpeechURL("https://DUMMY_DOMAIN/chime.mp3").
+				Build(),
+			want: &cek.OutputSpeech{
+				Type: cek.OutputSpeechTypeSpeechList,
+				Values: cek.SpeechInfoArray{
+					{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "晴れです。"},
+					{Lang: cek.SpeechInfoLangEmpty, Type: cek.SpeechInfoTypeURL, Value: "https://DUMMY_DOMAIN/chime.mp3"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.speech, tt.want) {
+				t.Errorf("Build() = %+v; want %+v", tt.speech, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseMessageRoundTrip(t *testing.T) {
+	speechSet := cek.NewOutputSpeechBuilder().
+		BriefText("天気予報です。", cek.SpeechInfoLangJA).
+		AddVerboseText("週末まで全国に梅雨…猛暑和らぐ。", cek.SpeechInfoLangJA).
+		AddVerboseText("明日全国的に梅雨…ところによって局地的に激しい雨に注意。", cek.SpeechInfoLangJA)
+	messages := []*cek.ResponseMessage{
+		cek.NewResponseBuilder().
+			OutputSpeech(cek.NewOutputSpeechBuilder().
+				AddSpeechText("歌を歌ってみます。", cek.SpeechInfoLangJA).
+				AddSpeechURL("https://DUMMY_DOMAIN/song.mp3").
+				Build()).
+			Build(),
+		cek.NewResponseBuilder().
+			OutputSpeech(speechSet.Build()).
+			Reprompt(speechSet.Build()).
+			Build(),
+		cek.NewResponseBuilder().
+			OutputSpeech(cek.NewOutputSpeechBuilder().
+				AddSpeechText("何枚注文しますか?", cek.SpeechInfoLangJA).
+				Build()).
+			Reprompt(cek.NewOutputSpeechBuilder().
+				BriefText("何枚ですか?", cek.SpeechInfoLangJA).
+				AddVerboseText("お言葉がなければ、注文をキャンセルしてよろしいですか?", cek.SpeechInfoLangJA).
+				Build()).
+			Build(),
+	}
+	for i, message := range messages {
+		if violations := message.Validate(); len(violations) > 0 {
+			t.Errorf("message %d: %v", i, violations)
+		}
+		b, err := json.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := &cek.ResponseMessage{}
+		if err := json.Unmarshal(b, got); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, message) {
+			t.Errorf("message %d: decoded %s", i, b)
+		}
+	}
+}
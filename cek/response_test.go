@@ -0,0 +1,182 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestResponseMessageUnmarshalJSON(t *testing.T) {
+	message := cek.NewResponseBuilder().
+		OutputSpeech(cek.NewOutputSpeechBuilder().
+			AddSpeechText("再生します。", cek.SpeechInfoLangJA).
+			Build()).
+		AddDirective(cek.NewAudioPlayerPlayDirective(&cek.AudioPlayerPlayPayload{
+			AudioItem: &cek.AudioItem{
+				AudioItemID: "item-1",
+				Stream: &cek.AudioStream{
+					Token:       "token-1",
+					URL:         "https://DUMMY_DOMAIN/song.mp3",
+					URLPlayable: true,
+				},
+				Title: "song",
+			},
+			PlayBehavior: cek.PlayBehaviorReplaceAll,
+			Source:       &cek.AudioSource{Name: "source"},
+		})).
+		AddDirective(cek.NewAudioPlayerStreamDeliverDirective(&cek.AudioPlayerStreamDeliverPayload{
+			AudioItemID: "item-1",
+			AudioStream: &cek.AudioStream{Token: "token-1", URL: "https://DUMMY_DOMAIN/song.mp3"},
+		})).
+		AddDirective(cek.NewPlaybackControllerDirective(cek.DirectiveStop)).
+		AddDirective(cek.NewDirective("Custom", "Unknown", map[string]interface{}{"count": 1.0})).
+		ShouldEndSession(true).
+		Build()
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &cek.ResponseMessage{}
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, message) {
+		t.Errorf("decoded %+v; want %+v", got.Response, message.Response)
+	}
+	if _, ok := got.Response.Directives[0].Payload.(*cek.AudioPlayerPlayPayload); !ok {
+		t.Errorf("Play payload %T", got.Response.Directives[0].Payload)
+	}
+	if _, ok := got.Response.OutputSpeech.Values.(*cek.SpeechInfo); !ok {
+		t.Errorf("values %T", got.Response.OutputSpeech.Values)
+	}
+}
+
+func TestOutputSpeechUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *cek.OutputSpeech
+		wantErr bool
+	}{
+		{
+			name: "SpeechList with a single speech",
+			body: `{"type": "SpeechList", "values": [{"type": "PlainText", "lang": "ja", "value": "はい"}]}`,
+			want: &cek.OutputSpeech{
+				Type:   cek.OutputSpeechTypeSpeechList,
+				Values: cek.SpeechInfoArray{{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "はい"}},
+			},
+		},
+		{
+			name: "SpeechSet",
+			body: `{"type": "SpeechSet", "brief": {"type": "PlainText", "lang": "ja", "value": "はい"},
+				"verbose": {"type": "SimpleSpeech", "values": {"type": "URL", "lang": "", "value": "https://DUMMY_DOMAIN/a.mp3"}}}`,
+			want: &cek.OutputSpeech{
+				Type:  cek.OutputSpeechTypeSpeechSet,
+				Brief: &cek.SpeechInfo{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "はい"},
+				Verbose: &cek.Verbose{
+					Type:   cek.OutputSpeechVerboseTypeSimpleSpeech,
+					Values: &cek.SpeechInfo{Type: cek.SpeechInfoTypeURL, Value: "https://DUMMY_DOMAIN/a.mp3"},
+				},
+			},
+		},
+		{
+			name:    "SimpleSpeech with a list",
+			body:    `{"type": "SimpleSpeech", "values": [{"type": "PlainText", "lang": "ja", "value": "はい"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &cek.OutputSpeech{}
+			err := json.Unmarshal([]byte(tt.body), got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseCardUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		card string
+		want interface{}
+	}{
+		{
+			name: "ImageText",
+			card: `{"type": "ImageText", "imageUrl": {"type": "url", "value": "https://DUMMY_DOMAIN/pizza.png"}, "mainText": {"type": "string", "value": "ペパロニ"}, "subTextList": [{"type": "string", "value": "1,200円"}], "highlightText": {"type": "string", "value": "人気"}}`,
+			want: &cek.ImageTextCard{
+				ImageURL:    &cek.CardField{Type: cek.CardFieldTypeURL, Value: "https://DUMMY_DOMAIN/pizza.png"},
+				MainText:    &cek.CardField{Type: cek.CardFieldTypeString, Value: "ペパロニ"},
+				SubTextList: []*cek.CardField{{Type: cek.CardFieldTypeString, Value: "1,200円"}},
+				Extra:       map[string]json.RawMessage{"highlightText": json.RawMessage(`{"type": "string", "value": "人気"}`)},
+			},
+		},
+		{
+			name: "ImageList",
+			card: `{"type": "ImageList", "imageList": [{"imageUrl": {"type": "url", "value": "https://DUMMY_DOMAIN/1.png"}, "referenceText": {"type": "string", "value": "ピザ"}}]}`,
+			want: &cek.ImageListCard{
+				ImageList: []*cek.ImageListItem{{
+					ImageURL:      &cek.CardField{Type: cek.CardFieldTypeURL, Value: "https://DUMMY_DOMAIN/1.png"},
+					ReferenceText: &cek.CardField{Type: cek.CardFieldTypeString, Value: "ピザ"},
+				}},
+			},
+		},
+		{
+			name: "unknown type",
+			card: `{"type": "Weather", "location": "Tokyo"}`,
+			want: map[string]interface{}{"type": "Weather", "location": "Tokyo"},
+		},
+		{
+			name: "empty",
+			card: `{}`,
+			want: map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &cek.Response{}
+			if err := json.Unmarshal([]byte(`{"card": `+tt.card+`, "directives": [], "shouldEndSession": true}`), response); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(response.Card, tt.want) || !response.ShouldEndSession {
+				t.Errorf("Card = %#v; want %#v", response.Card, tt.want)
+			}
+
+			b, err := json.Marshal(response.Card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.card), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Marshal() = %s; want %s", b, tt.card)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/base64"
 	"encoding/pem"
 	"errors"
+	"fmt"
 )
 
 var publicKeyStr = `
@@ -35,6 +36,18 @@ UwIDAQAB
 -----END PUBLIC KEY-----
 `
 
+// SignatureHeader is the header carrying the signature of the request body
+const SignatureHeader = "SignatureCEK"
+
+// ValidateSignature function verifies the signature of a request body sent by
+// the Clova platform. The error wraps ErrInvalidSignature.
+func ValidateSignature(signature string, body []byte) error {
+	if err := validateSignature(signature, body); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err.Error())
+	}
+	return nil
+}
+
 func validateSignature(signature string, body []byte) error {
 	sig, err := base64.StdEncoding.DecodeString(signature)
 	if err != nil {
@@ -0,0 +1,128 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Query parameters added to signed stream URLs
+const (
+	StreamURLExpiresParam   = "expires"
+	StreamURLSignatureParam = "signature"
+)
+
+// Errors returned by StreamURLSigner.Verify
+var (
+	ErrStreamURLExpired          = errors.New("stream URL expired")
+	ErrStreamURLInvalidSignature = errors.New("invalid stream URL signature")
+)
+
+// StreamURLSigner type signs stream URLs with HMAC-SHA256 and an expiry, so
+// that the URLs put into AudioPlayer directives cannot be shared forever. The
+// path and the query are signed; the scheme and the host are not, so that the
+// media server can run behind a CDN or a proxy.
+type StreamURLSigner struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewStreamURLSigner function
+func NewStreamURLSigner(key []byte, ttl time.Duration) *StreamURLSigner {
+	return &StreamURLSigner{
+		key: key,
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// Sign method returns rawURL signed to expire after the TTL of the signer. A
+// previous signature of rawURL is replaced.
+func (s *StreamURLSigner) Sign(rawURL string) (string, error) {
+	return s.SignWithExpiry(rawURL, s.now().Add(s.ttl))
+}
+
+// SignWithExpiry method returns rawURL signed to expire at expiry
+func (s *StreamURLSigner) SignWithExpiry(rawURL string, expiry time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Del(StreamURLSignatureParam)
+	query.Set(StreamURLExpiresParam, strconv.FormatInt(expiry.Unix(), 10))
+	query.Set(StreamURLSignatureParam, s.signature(u.EscapedPath(), query))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// SignStream method signs the URL of the stream in place
+func (s *StreamURLSigner) SignStream(stream *AudioStream) error {
+	signed, err := s.Sign(stream.URL)
+	if err != nil {
+		return err
+	}
+	stream.URL = signed
+	return nil
+}
+
+// Verify method checks the signature and the expiry of u
+func (s *StreamURLSigner) Verify(u *url.URL) error {
+	query := u.Query()
+	signature := query.Get(StreamURLSignatureParam)
+	if signature == "" {
+		return ErrStreamURLInvalidSignature
+	}
+	query.Del(StreamURLSignatureParam)
+	if !hmac.Equal([]byte(signature), []byte(s.signature(u.EscapedPath(), query))) {
+		return ErrStreamURLInvalidSignature
+	}
+	expires, err := strconv.ParseInt(query.Get(StreamURLExpiresParam), 10, 64)
+	if err != nil {
+		return ErrStreamURLInvalidSignature
+	}
+	if s.now().Unix() > expires {
+		return ErrStreamURLExpired
+	}
+	return nil
+}
+
+// Middleware method returns a handler serving only the requests with a valid
+// signed URL. Other requests get 403 Forbidden.
+func (s *StreamURLSigner) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Verify(r.URL); err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *StreamURLSigner) signature(path string, query url.Values) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(path))
+	mac.Write([]byte{'?'})
+	mac.Write([]byte(query.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
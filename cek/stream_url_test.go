@@ -0,0 +1,135 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestStreamURLSigner(t *testing.T) {
+	signer := cek.NewStreamURLSigner([]byte("secret"), time.Hour)
+	signed, err := signer.Sign("https://DUMMY_DOMAIN/media/song.mp3?quality=high")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := signer.SignWithExpiry("https://DUMMY_DOMAIN/media/song.mp3", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resigned, err := signer.Sign(expired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		url        string
+		wantStatus int
+	}{
+		{url: signed, wantStatus: http.StatusOK},
+		{url: resigned, wantStatus: http.StatusOK},
+		{url: expired, wantStatus: http.StatusForbidden},
+		{url: strings.Replace(signed, "quality=high", "quality=low", 1), wantStatus: http.StatusForbidden},
+		{url: strings.Replace(signed, "song.mp3", "other.mp3", 1), wantStatus: http.StatusForbidden},
+		{url: "https://DUMMY_DOMAIN/media/song.mp3", wantStatus: http.StatusForbidden},
+		{url: signed, wantStatus: http.StatusForbidden},
+	}
+	media := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	for i, testCase := range testCases {
+		h := signer.Middleware(media)
+		if i == len(testCases)-1 {
+			// signed with another key
+			h = cek.NewStreamURLSigner([]byte("other"), time.Hour).Middleware(media)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", testCase.url, nil))
+		if w.Code != testCase.wantStatus {
+			t.Errorf("Status %d: %d; want %d", i, w.Code, testCase.wantStatus)
+		}
+	}
+}
+
+func TestAudioQueueHandlerSignedStream(t *testing.T) {
+	ctx := context.Background()
+	signer := cek.NewStreamURLSigner([]byte("secret"), time.Hour)
+	h := cek.NewAudioQueueHandler(cek.NewMemoryAudioQueueStore(), nil)
+	h.Signer = signer
+
+	response, err := h.Play(ctx, newAudioRequest(&cek.LaunchRequest{}, 0), cek.NewAudioQueue(testTracks...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(playedStream(t, response).URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Verify(u); err != nil {
+		t.Errorf("Play URL %s: %v", u, err)
+	}
+
+	response, err = h.ServeCEK(ctx, newAudioRequest(&cek.EventRequest{
+		Event: &cek.Event{
+			Namespace: "AudioPlayer",
+			Name:      "StreamRequested",
+			Payload: map[string]interface{}{
+				"audioItemId": "track-2",
+				"audioStream": map[string]interface{}{"token": "track-2", "url": "https://DUMMY_DOMAIN/2.mp3", "urlPlayable": false},
+			},
+		},
+	}, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Response.Directives) != 1 || response.Response.Directives[0].Header.Name != cek.DirectiveStreamDeliver {
+		t.Fatalf("Directives: %v", response.Response.Directives)
+	}
+	payload := response.Response.Directives[0].Payload.(*cek.AudioPlayerStreamDeliverPayload)
+	u, err = url.Parse(payload.AudioStream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.AudioItemID != "track-2" || u.Path != "/2.mp3" || !payload.AudioStream.URLPlayable {
+		t.Errorf("StreamDeliver payload: %v %v", payload.AudioItemID, payload.AudioStream)
+	}
+	if err := signer.Verify(u); err != nil {
+		t.Errorf("StreamDeliver URL %s: %v", u, err)
+	}
+
+	response, err = h.ServeCEK(ctx, newAudioRequest(&cek.EventRequest{
+		Event: &cek.Event{
+			Namespace: "AudioPlayer",
+			Name:      "StreamRequested",
+			Payload: map[string]interface{}{
+				"audioItemId": "track-9",
+				"audioStream": map[string]interface{}{"token": "track-9", "url": "https://DUMMY_DOMAIN/private/9.mp3", "urlPlayable": false},
+			},
+		},
+	}, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Response.Directives) != 0 {
+		t.Errorf("Directives for a track not queued: %v", response.Response.Directives)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Span names used by Extension
+const (
+	SpanServeHTTP        = "cek.ServeHTTP"
+	SpanProcess          = "cek.Process"
+	SpanParseRequest     = "cek.ParseRequest"
+	SpanReadBody         = "cek.ReadBody"
+	SpanVerifySignature  = "cek.VerifySignature"
+	SpanDecodeRequest    = "cek.DecodeRequest"
+	SpanCheckApplication = "cek.CheckApplication"
+	SpanValidateRequest  = "cek.ValidateRequest"
+	SpanCheckReplay      = "cek.CheckReplay"
+	SpanHandle           = "cek.Handle"
+	SpanEncodeResponse   = "cek.EncodeResponse"
+)
+
+// Span attribute keys used by Extension
+const (
+	AttributeRequestType = "cek.request_type"
+	AttributeIntentName  = "cek.intent_name"
+	AttributeEventName   = "cek.event_name"
+	AttributeSessionID   = "cek.session_id"
+)
+
+// Tracer interface is the small subset of a tracing API needed by Extension.
+// It can be implemented on top of OpenTelemetry without the SDK depending on
+// it.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span interface
+type Span interface {
+	SetAttribute(key, value string)
+	RecordError(err error)
+	End()
+}
+
+// WithTracer function
+func WithTracer(t Tracer) ExtensionOption {
+	return func(ext *Extension) {
+		ext.tracer = t
+	}
+}
+
+func (e *Extension) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if e.tracer == nil {
+		return NoopTracer{}.Start(ctx, name)
+	}
+	return e.tracer.Start(ctx, name)
+}
+
+// trace runs f in a child span of ctx, recording the error it returns
+func (e *Extension) trace(ctx context.Context, name string, f func() error) error {
+	_, span := e.startSpan(ctx, name)
+	defer span.End()
+	if err := f(); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func setMessageAttributes(span Span, message *RequestMessage) {
+	requestType, name := requestTypeAndName(message.Request)
+	span.SetAttribute(AttributeRequestType, string(requestType))
+	switch requestType {
+	case RequestTypeIntent:
+		span.SetAttribute(AttributeIntentName, name)
+	case RequestTypeEvent:
+		span.SetAttribute(AttributeEventName, name)
+	}
+	if message.Session != nil {
+		span.SetAttribute(AttributeSessionID, message.Session.SessionID)
+	}
+}
+
+// NoopTracer type is a Tracer that records nothing. It is used when no tracer
+// is given.
+type NoopTracer struct{}
+
+// Start method for implementing Tracer interface
+func (NoopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key, value string) {}
+func (noopSpan) RecordError(err error)          {}
+func (noopSpan) End()                           {}
+
+// RecordingTracer type keeps ended spans in memory. It is intended for tests.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan type
+type RecordedSpan struct {
+	Name       string
+	Parent     *RecordedSpan
+	Attributes map[string]string
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+
+	tracer *RecordingTracer
+}
+
+type recordedSpanContextKey struct{}
+
+// NewRecordingTracer function
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// Start method for implementing Tracer interface
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(recordedSpanContextKey{}).(*RecordedSpan)
+	span := &RecordedSpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: map[string]string{},
+		StartTime:  time.Now(),
+		tracer:     t,
+	}
+	return context.WithValue(ctx, recordedSpanContextKey{}, span), span
+}
+
+// Spans method returns the ended spans in the order they ended
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+// Span method returns the last ended span with the name, or nil
+func (t *RecordingTracer) Span(name string) *RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.spans) - 1; i >= 0; i-- {
+		if t.spans[i].Name == name {
+			return t.spans[i]
+		}
+	}
+	return nil
+}
+
+// SetAttribute method for implementing Span interface
+func (s *RecordedSpan) SetAttribute(key, value string) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// RecordError method for implementing Span interface
+func (s *RecordedSpan) RecordError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.Errors = append(s.Errors, err)
+}
+
+// End method for implementing Span interface
+func (s *RecordedSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.EndTime = time.Now()
+	s.tracer.spans = append(s.tracer.spans, s)
+}
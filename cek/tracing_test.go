@@ -0,0 +1,93 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestTracing(t *testing.T) {
+	tracer := cek.NewRecordingTracer()
+	ext := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithTracer(tracer),
+		cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			return cek.NewResponseBuilder().Build(), nil
+		})))
+	// the debug mode skips the signature verification span
+	debugExt := cek.NewExtension("com.yourdomain.extension.pizzabot",
+		cek.WithDebugMode,
+		cek.WithTracer(tracer),
+		cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+			return cek.NewResponseBuilder().Build(), nil
+		})))
+
+	debugExt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1])))
+
+	var names []string
+	for _, span := range tracer.Spans() {
+		names = append(names, span.Name)
+	}
+	wantNames := []string{
+		cek.SpanReadBody,
+		cek.SpanDecodeRequest,
+		cek.SpanCheckApplication,
+		cek.SpanParseRequest,
+		cek.SpanHandle,
+		cek.SpanEncodeResponse,
+		cek.SpanServeHTTP,
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("Spans %v; want %v", names, wantNames)
+	}
+	root := tracer.Span(cek.SpanServeHTTP)
+	for _, span := range tracer.Spans() {
+		switch span.Name {
+		case cek.SpanServeHTTP:
+			if span.Parent != nil {
+				t.Errorf("Span %s has parent %s", span.Name, span.Parent.Name)
+			}
+		case cek.SpanReadBody, cek.SpanDecodeRequest, cek.SpanCheckApplication:
+			if span.Parent != tracer.Span(cek.SpanParseRequest) {
+				t.Errorf("Span %s is not a child of %s", span.Name, cek.SpanParseRequest)
+			}
+		default:
+			if span.Parent != root {
+				t.Errorf("Span %s is not a child of %s", span.Name, cek.SpanServeHTTP)
+			}
+		}
+	}
+	for _, name := range []string{cek.SpanParseRequest, cek.SpanHandle, cek.SpanServeHTTP} {
+		attributes := tracer.Span(name).Attributes
+		if attributes[cek.AttributeIntentName] != "OrderPizza" ||
+			attributes[cek.AttributeSessionID] != "a29cfead-c5ba-474d-8745-6c1a6625f0c5" {
+			t.Errorf("Span %s attributes: %v", name, attributes)
+		}
+	}
+
+	ext.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[1])))
+	span := tracer.Span(cek.SpanVerifySignature)
+	if span == nil || len(span.Errors) != 1 {
+		t.Fatalf("Span %s: %v", cek.SpanVerifySignature, span)
+	}
+	if tracer.Span(cek.SpanServeHTTP).Errors == nil {
+		t.Errorf("Span %s has no error", cek.SpanServeHTTP)
+	}
+}
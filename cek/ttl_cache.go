@@ -0,0 +1,86 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"container/list"
+	"time"
+)
+
+// ttlCache holds values until they expire, and at most capacity values,
+// dropping the oldest first when full. It is not safe for concurrent use.
+type ttlCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type ttlEntry struct {
+	key    string
+	value  interface{}
+	expiry time.Time
+}
+
+func newTTLCache(capacity int) *ttlCache {
+	return &ttlCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	c.purge()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*ttlEntry)
+	if !c.now().Before(entry.expiry) {
+		c.remove(elem)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache) add(key string, value interface{}, expiry time.Time) {
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	for c.order.Len() >= c.capacity && c.order.Len() > 0 {
+		c.remove(c.order.Front())
+	}
+	c.entries[key] = c.order.PushBack(&ttlEntry{key: key, value: value, expiry: expiry})
+}
+
+func (c *ttlCache) len() int {
+	c.purge()
+	return c.order.Len()
+}
+
+// purge drops the expired values at the front, which are the oldest
+func (c *ttlCache) purge() {
+	now := c.now()
+	for front := c.order.Front(); front != nil && !now.Before(front.Value.(*ttlEntry).expiry); front = c.order.Front() {
+		c.remove(front)
+	}
+}
+
+func (c *ttlCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*ttlEntry).key)
+}
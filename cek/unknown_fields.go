@@ -0,0 +1,283 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WithExtraMembers function makes the extension keep the JSON members of the
+// requests the SDK does not know in the Extra members of the decoded types.
+// Finding them takes one more scan of the body, so they are dropped by default.
+func WithExtraMembers(ext *Extension) {
+	ext.extraMembers = true
+}
+
+// WithStrictDecoding function makes the extension log a warning for the
+// requests having JSON members the SDK does not know, which are kept in the
+// Extra members of the decoded types as with WithExtraMembers. The warning
+// goes to the logger set with WithLogger, or to the default logger.
+func WithStrictDecoding(ext *Extension) {
+	ext.strictDecoding = true
+}
+
+// UnmarshalRequestMessage function decodes data into m like json.Unmarshal,
+// keeping the members the SDK does not know in the Extra members of m and of
+// the types it holds
+func UnmarshalRequestMessage(data []byte, m *RequestMessage) error {
+	if err := json.Unmarshal(data, m); err != nil {
+		return err
+	}
+	collectMessageExtra(data, m)
+	return nil
+}
+
+// collectMessageExtra sets the Extra members of m, decoded from data
+func collectMessageExtra(data []byte, m *RequestMessage) {
+	collectExtra(data, skipSpace(data, 0), reflect.ValueOf(m).Elem(), "")
+}
+
+func (e *Extension) warnUnknownFields(ctx context.Context, message *RequestMessage) {
+	fields := message.UnknownFields()
+	if len(fields) == 0 {
+		return
+	}
+	logger := e.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.LogAttrs(ctx, slog.LevelWarn, "cek unknown fields",
+		slog.String("extension_id", e.ID),
+		slog.Any("fields", fields))
+}
+
+// UnknownFields method returns the paths of the JSON members of the message
+// the SDK does not know, like "context.System.device.foo", sorted
+func (m *RequestMessage) UnknownFields() []string {
+	var fields []string
+	collectUnknownFields(reflect.ValueOf(m), "", &fields)
+	sort.Strings(fields)
+	return fields
+}
+
+var extraType = reflect.TypeOf(map[string]json.RawMessage{})
+
+func collectUnknownFields(v reflect.Value, path string, fields *[]string) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectUnknownFields(v.Elem(), path, fields)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.Ptr {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			collectUnknownFields(iter.Value(), joinPath(path, iter.Key().String()), fields)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Name == "Extra" && field.Type == extraType {
+				for name := range v.Field(i).Interface().(map[string]json.RawMessage) {
+					*fields = append(*fields, joinPath(path, name))
+				}
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			collectUnknownFields(v.Field(i), joinPath(path, name), fields)
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// structFields holds the JSON member names of a struct type with the indexes
+// of their fields
+type structFields struct {
+	names []string
+	index []int
+	// extra is the index of the Extra field, or -1
+	extra int
+}
+
+// structFieldsCache caches the structFields of the types
+var structFieldsCache sync.Map
+
+func fieldsOf(t reflect.Type) *structFields {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.(*structFields)
+	}
+	fields := &structFields{extra: -1}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Name == "Extra" && field.Type == extraType {
+			fields.extra = i
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields.names = append(fields.names, name)
+		fields.index = append(fields.index, i)
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// field returns the index of the field for the member name, or -1. Names are
+// matched case insensitively like encoding/json does.
+func (f *structFields) field(name []byte) int {
+	for i, n := range f.names {
+		if n == string(name) {
+			return f.index[i]
+		}
+	}
+	for i, n := range f.names {
+		if bytes.EqualFold([]byte(n), name) {
+			return f.index[i]
+		}
+	}
+	return -1
+}
+
+// collectExtra sets the Extra fields of v, a struct decoded from the JSON
+// object starting at i in b, and of the structs it holds, to the members their
+// types do not have, and returns the index after the object. known is one more
+// member name of v. b must be valid JSON, as decoded before. Nothing is
+// allocated unless there are unknown members.
+func collectExtra(b []byte, i int, v reflect.Value, known string) int {
+	fields := fieldsOf(v.Type())
+	var extra reflect.Value
+	if fields.extra >= 0 {
+		extra = v.Field(fields.extra)
+		extra.Set(reflect.Zero(extraType))
+	}
+	return scanMembers(b, i, func(name []byte, i int) int {
+		if index := fields.field(name); index >= 0 {
+			return collectValueExtra(b, i, v.Field(index))
+		}
+		end := skipValue(b, i)
+		if string(name) == known || !extra.IsValid() {
+			return end
+		}
+		if extra.IsNil() {
+			extra.Set(reflect.MakeMap(extraType))
+		}
+		value := json.RawMessage(append([]byte(nil), b[i:end]...))
+		extra.SetMapIndex(reflect.ValueOf(string(name)), reflect.ValueOf(value))
+		return end
+	})
+}
+
+// collectValueExtra collects the unknown members of the structs in v, decoded
+// from the JSON value starting at i in b, and returns the index after the
+// value
+func collectValueExtra(b []byte, i int, v reflect.Value) int {
+	if b[i] != '{' {
+		return skipValue(b, i)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return collectExtra(b, i, v, "")
+	case reflect.Ptr:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			return collectExtra(b, i, v.Elem(), "")
+		}
+	case reflect.Interface:
+		// the requests are held by the Request interface, and written with
+		// their type
+		if !v.IsNil() && v.Elem().Kind() == reflect.Ptr && v.Elem().Elem().Kind() == reflect.Struct {
+			return collectExtra(b, i, v.Elem().Elem(), "type")
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String && v.Type().Elem().Kind() == reflect.Ptr {
+			// the items are looked up by walking the map rather than indexing
+			// it, which would allocate the member names
+			var iter reflect.MapIter
+			key := reflect.New(v.Type().Key()).Elem()
+			return scanMembers(b, i, func(name []byte, i int) int {
+				iter.Reset(v)
+				for iter.Next() {
+					key.SetIterKey(&iter)
+					if key.String() == string(name) {
+						return collectValueExtra(b, i, iter.Value())
+					}
+				}
+				return skipValue(b, i)
+			})
+		}
+	}
+	return skipValue(b, i)
+}
+
+// marshalExtra encodes v, a pointer to a struct without a MarshalJSON method,
+// followed by the extra members
+func marshalExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf := bytes.NewBuffer(b[:len(b)-1])
+	for _, name := range names {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(extra[name])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
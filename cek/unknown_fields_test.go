@@ -0,0 +1,177 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestUnknownFields(t *testing.T) {
+	for i, body := range testRequestBodies {
+		message := &cek.RequestMessage{}
+		if err := cek.UnmarshalRequestMessage([]byte(body), message); err != nil {
+			t.Fatal(err)
+		}
+		if fields := message.UnknownFields(); len(fields) > 0 {
+			t.Errorf("UnknownFields %d: %v", i, fields)
+		}
+	}
+
+	body := strings.NewReplacer(
+		`"version": "1.0",`, `"version": "1.0", "trace": {"id": 1},`,
+		`"deviceId": "096e6b27-1717-33e9-b0a7-510a48658a9b",`, `"deviceId": "096e6b27-1717-33e9-b0a7-510a48658a9b", "model": "WAVE",`,
+		`"type": "IntentRequest",`, `"type": "IntentRequest", "locale": "ja-JP",`,
+		`"name": "pizzaType",`, `"name": "pizzaType", "confidence": 0.9,`,
+	).Replace(testRequestBodies[1])
+	message := &cek.RequestMessage{}
+	if err := cek.UnmarshalRequestMessage([]byte(body), message); err != nil {
+		t.Fatal(err)
+	}
+	wantFields := []string{
+		"context.System.device.model",
+		"request.intent.slots.pizzaType.confidence",
+		"request.locale",
+		"trace",
+	}
+	if got := message.UnknownFields(); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("UnknownFields() = %v; want %v", got, wantFields)
+	}
+	if got := string(message.Request.(*cek.IntentRequest).Extra["locale"]); got != `"ja-JP"` {
+		t.Errorf(`Extra["locale"] = %s`, got)
+	}
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(body), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Marshal() = %s; want %s", b, body)
+	}
+}
+
+func TestUnknownFieldsAudioStream(t *testing.T) {
+	body := strings.Replace(testRequestBodies[1], `"context": {`, `"context": {
+    "AudioPlayer": {
+      "playerActivity": "PLAYING",
+      "stream": {
+        "token": "track-1",
+        "url": "https://DUMMY_DOMAIN/1.mp3",
+        "bitrate": 128,
+        "progressReport": {"progressReportDelayInMilliseconds": 0, "mode": "delay"}
+      }
+    },`, 1)
+	message := &cek.RequestMessage{}
+	if err := cek.UnmarshalRequestMessage([]byte(body), message); err != nil {
+		t.Fatal(err)
+	}
+	wantFields := []string{
+		"context.AudioPlayer.stream.bitrate",
+		"context.AudioPlayer.stream.progressReport.mode",
+	}
+	if got := message.UnknownFields(); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("UnknownFields() = %v; want %v", got, wantFields)
+	}
+	stream := message.Context.AudioPlayer.Stream
+	if got := string(stream.Extra["bitrate"]); got != "128" {
+		t.Errorf(`Extra["bitrate"] = %s`, got)
+	}
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(body), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Marshal() = %s; want %s", b, body)
+	}
+
+	b, err = json.Marshal(&cek.AudioStream{Token: "track-1", URL: "https://DUMMY_DOMAIN/1.mp3", ProgressReport: &cek.ProgressReport{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"beginAtInMilliseconds":0,"progressReport":{},"token":"track-1","url":"https://DUMMY_DOMAIN/1.mp3","urlPlayable":false}`; string(b) != want {
+		t.Errorf("Marshal() = %s; want %s", b, want)
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	body := strings.Replace(testRequestBodies[2], `"version": "1.0",`, `"version": "1.0", "trace": {"id": 1},`, 1)
+	testCases := []struct {
+		option    cek.ExtensionOption
+		wantExtra bool
+		wantLog   bool
+	}{
+		{option: nil},
+		{option: cek.WithExtraMembers, wantExtra: true},
+		{option: cek.WithStrictDecoding, wantExtra: true, wantLog: true},
+	}
+	for i, testCase := range testCases {
+		buf := &bytes.Buffer{}
+		options := []cek.ExtensionOption{cek.WithDebugMode, cek.WithLogger(slog.New(slog.NewJSONHandler(buf, nil)))}
+		if testCase.option != nil {
+			options = append(options, testCase.option)
+		}
+		ext := cek.NewExtension("com.yourdomain.extension.pizzabot", options...)
+		message, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kept := string(message.Extra["trace"]) == `{"id": 1}`; kept != testCase.wantExtra {
+			t.Errorf(`%d: Extra["trace"] = %s`, i, message.Extra["trace"])
+		}
+		logged := strings.Contains(buf.String(), `"msg":"cek unknown fields"`) && strings.Contains(buf.String(), `"fields":["trace"]`)
+		if logged != testCase.wantLog {
+			t.Errorf("%d: log %s", i, buf)
+		}
+	}
+}
+
+func TestUnknownFieldsNames(t *testing.T) {
+	body := strings.NewReplacer(
+		`"version": "1.0",`, `"tr\u0061ce": 1, "Version": "1.0",`,
+		`"sessionId":`, `"SESSIONID":`,
+	).Replace(testRequestBodies[2])
+	message := &cek.RequestMessage{Extra: map[string]json.RawMessage{"stale": nil}}
+	if err := cek.UnmarshalRequestMessage([]byte(body), message); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := message.UnknownFields(), []string{"trace"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownFields() = %v; want %v", got, want)
+	}
+	if message.Version != "1.0" || message.Session.SessionID == "" {
+		t.Errorf("Version %q, SessionID %q", message.Version, message.Session.SessionID)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaxSessionAttributesSize is the maximum size in bytes of the JSON encoded
+// session attributes accepted by Validate
+const MaxSessionAttributesSize = 8 * 1024
+
+// ErrInvalidResponse is returned by ServeHTTP in strict mode when the handler
+// returns a response message with violations
+var ErrInvalidResponse = errors.New("invalid response")
+
+// Violation type is a part of a response message the platform would reject
+type Violation struct {
+	// Field is the JSON path of the offending field, like
+	// response.outputSpeech.values[0].value
+	Field   string
+	Message string
+}
+
+// String method
+func (v *Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// Violations type
+type Violations []*Violation
+
+// Error method for implementing error interface
+func (vs Violations) Error() string {
+	messages := make([]string, len(vs))
+	for i, v := range vs {
+		messages[i] = v.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// WithStrictResponses function makes ServeHTTP validate the response
+// messages of the handler. Messages with violations are logged with the
+// error class ErrorClassInvalidResponse and answered with 500 Internal Server
+// Error, instead of being rejected later by the platform.
+func WithStrictResponses(ext *Extension) {
+	ext.strictResponses = true
+}
+
+// Validate method checks the message against the limits of CEK and returns
+// the violations, or nil when the message is valid
+func (m *ResponseMessage) Validate() Violations {
+	v := &validator{}
+	if m == nil {
+		v.add("response", "missing")
+		return v.violations
+	}
+	if m.Response == nil {
+		v.add("response", "missing")
+	} else {
+		if m.Response.OutputSpeech != nil {
+			v.outputSpeech("response.outputSpeech", m.Response.OutputSpeech)
+		}
+		if reprompt := m.Response.Reprompt; reprompt != nil {
+			if reprompt.OutputSpeech == nil {
+				v.add("response.reprompt.outputSpeech", "missing")
+			} else {
+				v.outputSpeech("response.reprompt.outputSpeech", reprompt.OutputSpeech)
+			}
+		}
+	}
+	if len(m.SessionAttributes) > 0 {
+		b, err := json.Marshal(m.SessionAttributes)
+		if err != nil {
+			v.add("sessionAttributes", err.Error())
+		} else if len(b) > MaxSessionAttributesSize {
+			v.add("sessionAttributes", fmt.Sprintf("%d bytes exceed the limit of %d bytes", len(b), MaxSessionAttributesSize))
+		}
+	}
+	return v.violations
+}
+
+type validator struct {
+	violations Violations
+}
+
+func (v *validator) add(field, message string) {
+	v.violations = append(v.violations, &Violation{Field: field, Message: message})
+}
+
+func (v *validator) outputSpeech(field string, os *OutputSpeech) {
+	switch os.Type {
+	case OutputSpeechTypeSimpleSpeech, OutputSpeechTypeSpeechList:
+		v.values(field+".values", string(os.Type), os.Values)
+	case OutputSpeechTypeSpeechSet:
+		if os.Brief == nil {
+			v.add(field+".brief", "missing")
+		} else {
+			v.speechInfo(field+".brief", os.Brief)
+		}
+		if os.Verbose == nil {
+			v.add(field+".verbose", "missing")
+		} else {
+			v.values(field+".verbose.values", string(os.Verbose.Type), os.Verbose.Values)
+		}
+	default:
+		v.add(field+".type", fmt.Sprintf("unknown type %q", os.Type))
+	}
+}
+
+func (v *validator) values(field, speechType string, values SpeechInfoValues) {
+	switch speechType {
+	case string(OutputSpeechTypeSimpleSpeech):
+		info, ok := values.(*SpeechInfo)
+		if !ok || info == nil {
+			v.add(field, "SimpleSpeech needs a single speech")
+			return
+		}
+		v.speechInfo(field, info)
+	case string(OutputSpeechTypeSpeechList):
+		list, _ := values.(SpeechInfoArray)
+		if len(list) == 0 {
+			v.add(field, "SpeechList is empty")
+			return
+		}
+		for i, info := range list {
+			f := fmt.Sprintf("%s[%d]", field, i)
+			if info == nil {
+				v.add(f, "missing")
+				continue
+			}
+			v.speechInfo(f, info)
+		}
+	default:
+		v.add(field, fmt.Sprintf("unknown type %q", speechType))
+	}
+}
+
+func (v *validator) speechInfo(field string, info *SpeechInfo) {
+	switch info.Type {
+	case SpeechInfoTypePlainText:
+		if info.Lang == SpeechInfoLangEmpty {
+			v.add(field+".lang", "PlainText needs a language")
+		}
+		if info.Value == "" {
+			v.add(field+".value", "empty")
+		}
+	case SpeechInfoTypeURL:
+		u, err := url.Parse(info.Value)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			v.add(field+".value", fmt.Sprintf("%q is not an HTTPS URL", info.Value))
+		}
+	default:
+		v.add(field+".type", fmt.Sprintf("unknown type %q", info.Type))
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cek_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+func TestResponseMessageValidate(t *testing.T) {
+	speech := func(b *cek.OutputSpeechBuilder) *cek.OutputSpeech { return b.Build() }
+	text := &cek.SpeechInfo{Lang: cek.SpeechInfoLangJA, Type: cek.SpeechInfoTypePlainText, Value: "ピザ"}
+	testCases := []struct {
+		message *cek.ResponseMessage
+		want    []string
+	}{
+		{
+			message: cek.NewResponseBuilder().
+				OutputSpeech(speech(cek.NewOutputSpeechBuilder().AddSpeechText("ピザ", cek.SpeechInfoLangJA).AddSpeechURL("https://example.com/a.mp3"))).
+				Reprompt(speech(cek.NewOutputSpeechBuilder().AddSpeechText("ご注文は？", cek.SpeechInfoLangJA))).
+				Build(),
+		},
+		{
+			message: cek.NewResponseBuilder().Build(),
+		},
+		{
+			message: cek.NewResponseBuilder().OutputSpeech(speech(cek.NewOutputSpeechBuilder())).Build(),
+			want:    []string{"response.outputSpeech.values: SpeechList is empty"},
+		},
+		{
+			message: cek.NewResponseBuilder().
+				OutputSpeech(speech(cek.NewOutputSpeechBuilder().AddSpeechURL("http://example.com/a.mp3").AddSpeechText("", cek.SpeechInfoLangEmpty))).
+				Build(),
+			want: []string{
+				`response.outputSpeech.values[0].value: "http://example.com/a.mp3" is not an HTTPS URL`,
+				"response.outputSpeech.values[1].lang: PlainText needs a language",
+				"response.outputSpeech.values[1].value: empty",
+			},
+		},
+		{
+			message: cek.NewResponseBuilder().
+				OutputSpeech(&cek.OutputSpeech{Type: cek.OutputSpeechTypeSpeechSet, Brief: text}).
+				Build(),
+			want: []string{"response.outputSpeech.verbose: missing"},
+		},
+		{
+			message: &cek.ResponseMessage{Response: &cek.Response{Reprompt: &cek.Reprompt{}}},
+			want:    []string{"response.reprompt.outputSpeech: missing"},
+		},
+		{
+			message: cek.NewResponseBuilder().
+				SessionAttributes(map[string]string{"history": strings.Repeat("x", cek.MaxSessionAttributesSize)}).
+				Build(),
+			want: []string{"sessionAttributes: 8206 bytes exceed the limit of 8192 bytes"},
+		},
+	}
+	for i, testCase := range testCases {
+		var got []string
+		for _, v := range testCase.message.Validate() {
+			got = append(got, v.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(testCase.want, "\n") {
+			t.Errorf("Violations %d: %q; want %q", i, got, testCase.want)
+		}
+	}
+}
+
+func TestStrictResponses(t *testing.T) {
+	invalid := cek.NewResponseBuilder().OutputSpeech(cek.NewOutputSpeechBuilder().Build()).Build()
+	for _, strict := range []bool{false, true} {
+		tracer := cek.NewRecordingTracer()
+		options := []cek.ExtensionOption{
+			cek.WithDebugMode,
+			cek.WithHandler(cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+				return invalid, nil
+			})),
+			cek.WithTracer(tracer),
+		}
+		if strict {
+			options = append(options, cek.WithStrictResponses)
+		}
+		ext := cek.NewExtension("com.yourdomain.extension.pizzabot", options...)
+		w := httptest.NewRecorder()
+		ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBodies[2])))
+		wantStatus := http.StatusOK
+		if strict {
+			wantStatus = http.StatusInternalServerError
+		}
+		if w.Code != wantStatus {
+			t.Errorf("Status (strict %v): %d; want %d", strict, w.Code, wantStatus)
+		}
+		if errs := tracer.Span(cek.SpanServeHTTP).Errors; strict && (len(errs) != 1 || !errors.Is(errs[0], cek.ErrInvalidResponse)) {
+			t.Errorf("Errors: %v; want %v", errs, cek.ErrInvalidResponse)
+		}
+	}
+}
+
+func TestResponseMessageValidateNil(t *testing.T) {
+	var message *cek.ResponseMessage
+	violations := message.Validate()
+	if len(violations) != 1 || violations[0].Field != "response" || violations[0].Message != "missing" {
+		t.Errorf("Validate() = %v; want response: missing", violations)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package clovahome
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+// ErrNoHandler is returned when the extension has no handler to dispatch to
+var ErrNoHandler = errors.New("no handler")
+
+// ErrNoResponse is logged when the handler returns neither a response nor an
+// error
+var ErrNoResponse = errors.New("no response")
+
+// Handler interface
+type Handler interface {
+	ServeClovaHome(ctx context.Context, message *RequestMessage) (*ResponseMessage, error)
+}
+
+// HandlerFunc type
+type HandlerFunc func(ctx context.Context, message *RequestMessage) (*ResponseMessage, error)
+
+// ServeClovaHome method for implementing Handler interface
+func (f HandlerFunc) ServeClovaHome(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	return f(ctx, message)
+}
+
+// Extension type
+type Extension struct {
+	debugMode   bool
+	handler     Handler
+	logger      *slog.Logger
+	maxBodySize int64
+}
+
+// ExtensionOption type
+type ExtensionOption func(*Extension)
+
+// NewExtension function
+func NewExtension(options ...ExtensionOption) *Extension {
+	ext := &Extension{}
+	for _, option := range options {
+		option(ext)
+	}
+	return ext
+}
+
+// WithDebugMode function skips the signature validation
+func WithDebugMode(ext *Extension) {
+	ext.debugMode = true
+}
+
+// WithHandler function
+func WithHandler(h Handler) ExtensionOption {
+	return func(ext *Extension) {
+		ext.handler = h
+	}
+}
+
+// WithMaxBodySize function sets the size limit of the request bodies, which is
+// cek.DefaultMaxBodySize by default. Larger requests are rejected with
+// cek.ErrRequestTooLarge.
+func WithMaxBodySize(n int64) ExtensionOption {
+	return func(ext *Extension) {
+		ext.maxBodySize = n
+	}
+}
+
+// WithLogger function sets the logger of the handler errors
+func WithLogger(logger *slog.Logger) ExtensionOption {
+	return func(ext *Extension) {
+		ext.logger = logger
+	}
+}
+
+// ParseRequest method verifies the signature of the request, like
+// cek.Extension.ParseRequest, and decodes the message. The signature error
+// wraps cek.ErrInvalidSignature, and ErrUnknownRequest is returned for
+// messages which are not Clova Home requests.
+func (e *Extension) ParseRequest(r *http.Request) (*RequestMessage, error) {
+	defer r.Body.Close()
+	body, err := cek.ReadBody(r, e.maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	if !e.debugMode {
+		if err := cek.ValidateSignature(r.Header.Get(cek.SignatureHeader), body); err != nil {
+			return nil, err
+		}
+	}
+	message := &RequestMessage{}
+	if err := json.Unmarshal(body, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// ServeHTTP method parses the request, dispatches it to the handler and
+// writes the response message. Handler errors are answered with
+// DriverInternalError, as the platform expects an error message rather than
+// an HTTP error.
+func (e *Extension) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	message, err := e.ParseRequest(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, cek.ErrRequestTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	if e.handler == nil {
+		e.logError(r.Context(), message, ErrNoHandler)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	response, err := e.handler.ServeClovaHome(r.Context(), message)
+	if err == nil && response == nil {
+		err = ErrNoResponse
+	}
+	if err != nil {
+		e.logError(r.Context(), message, err)
+		response = message.Error(ErrorDriverInternal, nil)
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		e.logError(r.Context(), message, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.Write(body)
+}
+
+func (e *Extension) logError(ctx context.Context, message *RequestMessage, err error) {
+	if e.logger == nil {
+		return
+	}
+	e.logger.ErrorContext(ctx, "clova home request failed",
+		slog.String("name", message.Header.Name),
+		slog.String("message_id", message.Header.MessageID),
+		slog.String("error", err.Error()))
+}
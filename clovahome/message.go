@@ -0,0 +1,387 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package clovahome models the messages of Clova Home extensions, which let
+// users control smart home appliances. A request carries a directive of the
+// ClovaHome namespace, like DiscoverAppliancesRequest or TurnOnRequest, and
+// is answered by a response, a confirmation or an error of the same
+// namespace.
+package clovahome
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+// Namespace of the Clova Home messages
+const Namespace = "ClovaHome"
+
+// PayloadVersion of the Clova Home messages
+const PayloadVersion = "1.0"
+
+// ErrUnknownRequest is returned when the name of the request is not known
+var ErrUnknownRequest = errors.New("unknown request")
+
+// Request names
+const (
+	RequestDiscoverAppliances         = "DiscoverAppliancesRequest"
+	RequestHealthCheck                = "HealthCheckRequest"
+	RequestTurnOn                     = "TurnOnRequest"
+	RequestTurnOff                    = "TurnOffRequest"
+	RequestSetBrightness              = "SetBrightnessRequest"
+	RequestIncrementBrightness        = "IncrementBrightnessRequest"
+	RequestDecrementBrightness        = "DecrementBrightnessRequest"
+	RequestSetTargetTemperature       = "SetTargetTemperatureRequest"
+	RequestIncrementTargetTemperature = "IncrementTargetTemperatureRequest"
+	RequestDecrementTargetTemperature = "DecrementTargetTemperatureRequest"
+	RequestGetCurrentTemperature      = "GetCurrentTemperatureRequest"
+	RequestSetChannel                 = "SetChannelRequest"
+	RequestIncrementChannel           = "IncrementChannelRequest"
+	RequestDecrementChannel           = "DecrementChannelRequest"
+	RequestSetVolume                  = "SetVolumeRequest"
+	RequestIncrementVolume            = "IncrementVolumeRequest"
+	RequestDecrementVolume            = "DecrementVolumeRequest"
+)
+
+// ErrorName type
+type ErrorName string
+
+// ErrorName constants
+const (
+	ErrorDriverInternal            ErrorName = "DriverInternalError"
+	ErrorExpiredAccessToken        ErrorName = "ExpiredAccessTokenError"
+	ErrorInvalidAccessToken        ErrorName = "InvalidAccessTokenError"
+	ErrorNoSuchTarget              ErrorName = "NoSuchTargetError"
+	ErrorTargetOffline             ErrorName = "TargetOfflineError"
+	ErrorTargetHardwareMalfunction ErrorName = "TargetHardwareMalfunctionError"
+	ErrorUnsupportedOperation      ErrorName = "UnsupportedOperationError"
+	ErrorValueOutOfRange           ErrorName = "ValueOutOfRangeError"
+	ErrorConditionsNotMet          ErrorName = "ConditionsNotMetError"
+)
+
+// ApplianceType type
+type ApplianceType string
+
+// ApplianceType constants
+const (
+	ApplianceTypeLight          ApplianceType = "LIGHT"
+	ApplianceTypeSmartPlug      ApplianceType = "SMARTPLUG"
+	ApplianceTypeSwitch         ApplianceType = "SWITCH"
+	ApplianceTypeAirConditioner ApplianceType = "AIRCONDITIONER"
+	ApplianceTypeHeater         ApplianceType = "HEATER"
+	ApplianceTypeSmartTV        ApplianceType = "SMARTTV"
+)
+
+// Action type is the name of a request an appliance supports, without the
+// Request suffix
+type Action string
+
+// Action constants
+const (
+	ActionTurnOn                     Action = "TurnOn"
+	ActionTurnOff                    Action = "TurnOff"
+	ActionSetBrightness              Action = "SetBrightness"
+	ActionIncrementBrightness        Action = "IncrementBrightness"
+	ActionDecrementBrightness        Action = "DecrementBrightness"
+	ActionSetTargetTemperature       Action = "SetTargetTemperature"
+	ActionIncrementTargetTemperature Action = "IncrementTargetTemperature"
+	ActionDecrementTargetTemperature Action = "DecrementTargetTemperature"
+	ActionGetCurrentTemperature      Action = "GetCurrentTemperature"
+	ActionSetChannel                 Action = "SetChannel"
+	ActionIncrementChannel           Action = "IncrementChannel"
+	ActionDecrementChannel           Action = "DecrementChannel"
+	ActionSetVolume                  Action = "SetVolume"
+	ActionIncrementVolume            Action = "IncrementVolume"
+	ActionDecrementVolume            Action = "DecrementVolume"
+)
+
+// Header type
+type Header struct {
+	MessageID      string `json:"messageId"`
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	PayloadVersion string `json:"payloadVersion"`
+}
+
+// RequestMessage type. The payload is a *DiscoverAppliancesRequest, a
+// *HealthCheckRequest or an *ApplianceRequest depending on the name of the
+// header.
+type RequestMessage struct {
+	Header  *Header     `json:"header"`
+	Payload interface{} `json:"payload"`
+}
+
+// UnmarshalJSON method for RequestMessage
+func (m *RequestMessage) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Header  *Header         `json:"header"`
+		Payload json.RawMessage `json:"payload"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw.Header == nil || raw.Header.Namespace != Namespace {
+		return ErrUnknownRequest
+	}
+	var payload interface{}
+	switch raw.Header.Name {
+	case RequestDiscoverAppliances:
+		payload = &DiscoverAppliancesRequest{}
+	case RequestHealthCheck:
+		payload = &HealthCheckRequest{}
+	default:
+		if !isApplianceRequest(raw.Header.Name) {
+			return ErrUnknownRequest
+		}
+		payload = &ApplianceRequest{}
+	}
+	if len(raw.Payload) > 0 {
+		if err := json.Unmarshal(raw.Payload, payload); err != nil {
+			return err
+		}
+	}
+	m.Header = raw.Header
+	m.Payload = payload
+	return nil
+}
+
+func isApplianceRequest(name string) bool {
+	switch name {
+	case RequestTurnOn, RequestTurnOff,
+		RequestSetBrightness, RequestIncrementBrightness, RequestDecrementBrightness,
+		RequestSetTargetTemperature, RequestIncrementTargetTemperature, RequestDecrementTargetTemperature,
+		RequestGetCurrentTemperature,
+		RequestSetChannel, RequestIncrementChannel, RequestDecrementChannel,
+		RequestSetVolume, RequestIncrementVolume, RequestDecrementVolume:
+		return true
+	}
+	return false
+}
+
+// Action method returns the action requested by the message
+func (m *RequestMessage) Action() Action {
+	return Action(strings.TrimSuffix(m.Header.Name, "Request"))
+}
+
+// ApplianceRequest method returns the payload of a control or query request,
+// or nil
+func (m *RequestMessage) ApplianceRequest() *ApplianceRequest {
+	payload, _ := m.Payload.(*ApplianceRequest)
+	return payload
+}
+
+// AccessToken method returns the access token of the request, or an empty
+// string
+func (m *RequestMessage) AccessToken() string {
+	switch payload := m.Payload.(type) {
+	case *DiscoverAppliancesRequest:
+		return payload.AccessToken
+	case *ApplianceRequest:
+		return payload.AccessToken
+	}
+	return ""
+}
+
+// NewRequest function returns a request message with the name and payload,
+// for testing handlers
+func NewRequest(name string, payload interface{}) *RequestMessage {
+	return &RequestMessage{
+		Header: &Header{
+			MessageID:      cek.NewMessageID(),
+			Name:           name,
+			Namespace:      Namespace,
+			PayloadVersion: PayloadVersion,
+		},
+		Payload: payload,
+	}
+}
+
+// NewApplianceRequest function returns a control or query request for the
+// appliance. The value is set to the field the request name uses, like
+// Brightness for SetBrightnessRequest and DeltaBrightness for
+// IncrementBrightnessRequest; it is ignored by the other requests.
+func NewApplianceRequest(name, accessToken, applianceID string, value *Value) *RequestMessage {
+	request := &ApplianceRequest{
+		AccessToken: accessToken,
+		Appliance:   &Appliance{ApplianceID: applianceID},
+	}
+	switch name {
+	case RequestSetBrightness:
+		request.Brightness = value
+	case RequestIncrementBrightness, RequestDecrementBrightness:
+		request.DeltaBrightness = value
+	case RequestSetTargetTemperature:
+		request.TargetTemperature = value
+	case RequestIncrementTargetTemperature, RequestDecrementTargetTemperature:
+		request.DeltaTemperature = value
+	case RequestSetChannel:
+		request.Channel = value
+	case RequestIncrementChannel, RequestDecrementChannel:
+		request.DeltaChannel = value
+	case RequestSetVolume:
+		request.Volume = value
+	case RequestIncrementVolume, RequestDecrementVolume:
+		request.DeltaVolume = value
+	}
+	return NewRequest(name, request)
+}
+
+// DiscoverAppliancesRequest type
+type DiscoverAppliancesRequest struct {
+	AccessToken string `json:"accessToken"`
+}
+
+// HealthCheckRequest type
+type HealthCheckRequest struct {
+}
+
+// ApplianceRequest type is the payload of the requests controlling or
+// querying an appliance. Only the value of the request is set.
+type ApplianceRequest struct {
+	AccessToken       string     `json:"accessToken"`
+	Appliance         *Appliance `json:"appliance"`
+	Brightness        *Value     `json:"brightness,omitempty"`
+	DeltaBrightness   *Value     `json:"deltaBrightness,omitempty"`
+	TargetTemperature *Value     `json:"targetTemperature,omitempty"`
+	DeltaTemperature  *Value     `json:"deltaTemperature,omitempty"`
+	Channel           *Value     `json:"channel,omitempty"`
+	DeltaChannel      *Value     `json:"deltaChannel,omitempty"`
+	Volume            *Value     `json:"volume,omitempty"`
+	DeltaVolume       *Value     `json:"deltaVolume,omitempty"`
+}
+
+// Appliance type
+type Appliance struct {
+	ApplianceID                string            `json:"applianceId"`
+	AdditionalApplianceDetails map[string]string `json:"additionalApplianceDetails,omitempty"`
+}
+
+// Value type
+type Value struct {
+	Value float64 `json:"value"`
+}
+
+// NewValue function
+func NewValue(v float64) *Value {
+	return &Value{Value: v}
+}
+
+// ResponseMessage type
+type ResponseMessage struct {
+	Header  *Header     `json:"header"`
+	Payload interface{} `json:"payload"`
+}
+
+// DiscoverAppliancesResponse type
+type DiscoverAppliancesResponse struct {
+	DiscoveredAppliances []*DiscoveredAppliance `json:"discoveredAppliances"`
+}
+
+// DiscoveredAppliance type
+type DiscoveredAppliance struct {
+	ApplianceID                string            `json:"applianceId"`
+	ManufacturerName           string            `json:"manufacturerName"`
+	ModelName                  string            `json:"modelName"`
+	Version                    string            `json:"version"`
+	FriendlyName               string            `json:"friendlyName"`
+	FriendlyDescription        string            `json:"friendlyDescription"`
+	IsReachable                bool              `json:"isReachable"`
+	Actions                    []Action          `json:"actions"`
+	ApplianceTypes             []ApplianceType   `json:"applianceTypes"`
+	AdditionalApplianceDetails map[string]string `json:"additionalApplianceDetails,omitempty"`
+}
+
+// HealthCheckResponse type
+type HealthCheckResponse struct {
+	Description string `json:"description"`
+	IsHealthy   bool   `json:"isHealthy"`
+}
+
+// Confirmation type is the payload answering a control or query request.
+// Only the values changed or asked by the request are set.
+type Confirmation struct {
+	Brightness         *Value `json:"brightness,omitempty"`
+	TargetTemperature  *Value `json:"targetTemperature,omitempty"`
+	CurrentTemperature *Value `json:"currentTemperature,omitempty"`
+	Channel            *Value `json:"channel,omitempty"`
+	Volume             *Value `json:"volume,omitempty"`
+	PreviousState      *State `json:"previousState,omitempty"`
+}
+
+// State type
+type State struct {
+	Brightness        *Value `json:"brightness,omitempty"`
+	TargetTemperature *Value `json:"targetTemperature,omitempty"`
+	Channel           *Value `json:"channel,omitempty"`
+	Volume            *Value `json:"volume,omitempty"`
+}
+
+// ValueOutOfRangeError type is the payload of ErrorValueOutOfRange
+type ValueOutOfRangeError struct {
+	MinimumValue float64 `json:"minimumValue"`
+	MaximumValue float64 `json:"maximumValue"`
+}
+
+// ResponseName function returns the name of the message answering the
+// request name: DiscoverAppliancesResponse, HealthCheckResponse and
+// GetCurrentTemperatureResponse for queries, and a confirmation like
+// TurnOnConfirmation for controls
+func ResponseName(requestName string) string {
+	name := strings.TrimSuffix(requestName, "Request")
+	switch requestName {
+	case RequestDiscoverAppliances, RequestHealthCheck, RequestGetCurrentTemperature:
+		return name + "Response"
+	}
+	return name + "Confirmation"
+}
+
+// Respond method returns the response to the request with the payload, named
+// by ResponseName
+func (m *RequestMessage) Respond(payload interface{}) *ResponseMessage {
+	return m.response(ResponseName(m.Header.Name), payload)
+}
+
+// Error method returns the error response to the request. The payload is
+// empty when it is nil.
+func (m *RequestMessage) Error(name ErrorName, payload interface{}) *ResponseMessage {
+	if payload == nil {
+		payload = struct{}{}
+	}
+	return m.response(string(name), payload)
+}
+
+// ValueOutOfRange method returns the ErrorValueOutOfRange response to the
+// request
+func (m *RequestMessage) ValueOutOfRange(min, max float64) *ResponseMessage {
+	return m.Error(ErrorValueOutOfRange, &ValueOutOfRangeError{MinimumValue: min, MaximumValue: max})
+}
+
+func (m *RequestMessage) response(name string, payload interface{}) *ResponseMessage {
+	version := PayloadVersion
+	if m.Header != nil && m.Header.PayloadVersion != "" {
+		version = m.Header.PayloadVersion
+	}
+	return &ResponseMessage{
+		Header: &Header{
+			MessageID:      cek.NewMessageID(),
+			Name:           name,
+			Namespace:      Namespace,
+			PayloadVersion: version,
+		},
+		Payload: payload,
+	}
+}
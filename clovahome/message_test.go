@@ -0,0 +1,193 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package clovahome_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+	"github.com/line/clova-cek-sdk-go/clovahome"
+)
+
+func testRequestBody(name, payload string) string {
+	return `{
+  "header": {
+    "messageId": "295cc7a0-8f8d-4c56-b23e-2b1e67c0b4b0",
+    "name": "` + name + `",
+    "namespace": "ClovaHome",
+    "payloadVersion": "1.0"
+  },
+  "payload": ` + payload + `
+}`
+}
+
+func TestParseRequest(t *testing.T) {
+	ext := clovahome.NewExtension(clovahome.WithDebugMode)
+	testCases := []struct {
+		body        string
+		wantPayload interface{}
+		wantAction  clovahome.Action
+		wantErr     error
+	}{
+		{
+			body:        testRequestBody("DiscoverAppliancesRequest", `{"accessToken": "92b8c4f9"}`),
+			wantPayload: &clovahome.DiscoverAppliancesRequest{AccessToken: "92b8c4f9"},
+			wantAction:  "DiscoverAppliances",
+		},
+		{
+			body:        testRequestBody("HealthCheckRequest", `{}`),
+			wantPayload: &clovahome.HealthCheckRequest{},
+			wantAction:  "HealthCheck",
+		},
+		{
+			body: testRequestBody("TurnOnRequest", `{"accessToken": "92b8c4f9", "appliance": {"applianceId": "light-1"}}`),
+			wantPayload: &clovahome.ApplianceRequest{
+				AccessToken: "92b8c4f9",
+				Appliance:   &clovahome.Appliance{ApplianceID: "light-1"},
+			},
+			wantAction: clovahome.ActionTurnOn,
+		},
+		{
+			body: testRequestBody("SetBrightnessRequest", `{"accessToken": "92b8c4f9", "appliance": {"applianceId": "light-1"}, "brightness": {"value": 70}}`),
+			wantPayload: &clovahome.ApplianceRequest{
+				AccessToken: "92b8c4f9",
+				Appliance:   &clovahome.Appliance{ApplianceID: "light-1"},
+				Brightness:  clovahome.NewValue(70),
+			},
+			wantAction: clovahome.ActionSetBrightness,
+		},
+		{
+			body: testRequestBody("IncrementTargetTemperatureRequest", `{"accessToken": "92b8c4f9", "appliance": {"applianceId": "aircon-1"}, "deltaTemperature": {"value": 1.5}}`),
+			wantPayload: &clovahome.ApplianceRequest{
+				AccessToken:      "92b8c4f9",
+				Appliance:        &clovahome.Appliance{ApplianceID: "aircon-1"},
+				DeltaTemperature: clovahome.NewValue(1.5),
+			},
+			wantAction: clovahome.ActionIncrementTargetTemperature,
+		},
+		{
+			body:    testRequestBody("OpenDoorRequest", `{}`),
+			wantErr: clovahome.ErrUnknownRequest,
+		},
+		{
+			body:    strings.Replace(testRequestBody("TurnOnRequest", `{}`), "ClovaHome", "Clova", 1),
+			wantErr: clovahome.ErrUnknownRequest,
+		},
+	}
+	for i, testCase := range testCases {
+		message, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(testCase.body)))
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("Error %d: %v; want %v", i, err, testCase.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(message.Payload, testCase.wantPayload) {
+			t.Errorf("Payload %d: %#v; want %#v", i, message.Payload, testCase.wantPayload)
+		}
+		if message.Action() != testCase.wantAction {
+			t.Errorf("Action %d: %s; want %s", i, message.Action(), testCase.wantAction)
+		}
+	}
+}
+
+func TestParseRequestSignature(t *testing.T) {
+	ext := clovahome.NewExtension()
+	req := httptest.NewRequest("POST", "/", strings.NewReader(testRequestBody("HealthCheckRequest", `{}`)))
+	req.Header.Set(cek.SignatureHeader, "aW52YWxpZA==")
+	if _, err := ext.ParseRequest(req); !errors.Is(err, cek.ErrInvalidSignature) {
+		t.Errorf("Error: %v; want %v", err, cek.ErrInvalidSignature)
+	}
+}
+
+func TestParseRequestTooLarge(t *testing.T) {
+	ext := clovahome.NewExtension(clovahome.WithDebugMode, clovahome.WithMaxBodySize(64))
+	body := testRequestBody("HealthCheckRequest", `{}`)
+	if _, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(body))); !errors.Is(err, cek.ErrRequestTooLarge) {
+		t.Errorf("ParseRequest: %v; want %v", err, cek.ErrRequestTooLarge)
+	}
+	w := httptest.NewRecorder()
+	ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Status: %d; want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestResponses(t *testing.T) {
+	ext := clovahome.NewExtension(clovahome.WithDebugMode)
+	parse := func(name string) *clovahome.RequestMessage {
+		message, err := ext.ParseRequest(httptest.NewRequest("POST", "/", strings.NewReader(
+			testRequestBody(name, `{"accessToken": "92b8c4f9", "appliance": {"applianceId": "light-1"}}`))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return message
+	}
+	testCases := []struct {
+		response *clovahome.ResponseMessage
+		want     string
+	}{
+		{
+			response: parse("DiscoverAppliancesRequest").Respond(&clovahome.DiscoverAppliancesResponse{}),
+			want:     `{"name":"DiscoverAppliancesResponse","payload":{"discoveredAppliances":null}}`,
+		},
+		{
+			response: parse("HealthCheckRequest").Respond(&clovahome.HealthCheckResponse{Description: "ok", IsHealthy: true}),
+			want:     `{"name":"HealthCheckResponse","payload":{"description":"ok","isHealthy":true}}`,
+		},
+		{
+			response: parse("GetCurrentTemperatureRequest").Respond(&clovahome.Confirmation{CurrentTemperature: clovahome.NewValue(23)}),
+			want:     `{"name":"GetCurrentTemperatureResponse","payload":{"currentTemperature":{"value":23}}}`,
+		},
+		{
+			response: parse("SetBrightnessRequest").Respond(&clovahome.Confirmation{
+				Brightness:    clovahome.NewValue(70),
+				PreviousState: &clovahome.State{Brightness: clovahome.NewValue(30)},
+			}),
+			want: `{"name":"SetBrightnessConfirmation","payload":{"brightness":{"value":70},"previousState":{"brightness":{"value":30}}}}`,
+		},
+		{
+			response: parse("TurnOnRequest").Error(clovahome.ErrorTargetOffline, nil),
+			want:     `{"name":"TargetOfflineError","payload":{}}`,
+		},
+		{
+			response: parse("SetBrightnessRequest").ValueOutOfRange(0, 100),
+			want:     `{"name":"ValueOutOfRangeError","payload":{"minimumValue":0,"maximumValue":100}}`,
+		},
+	}
+	for i, testCase := range testCases {
+		header := testCase.response.Header
+		if header.Namespace != clovahome.Namespace || header.PayloadVersion != clovahome.PayloadVersion || header.MessageID == "" {
+			t.Errorf("Header %d: %+v", i, header)
+		}
+		b, err := json.Marshal(struct {
+			Name    string      `json:"name"`
+			Payload interface{} `json:"payload"`
+		}{header.Name, testCase.response.Payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != testCase.want {
+			t.Errorf("Response %d: %s; want %s", i, b, testCase.want)
+		}
+	}
+}
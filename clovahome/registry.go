@@ -0,0 +1,215 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package clovahome
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+// Errors returned by MemoryApplianceRegistry.Register
+var (
+	ErrNoApplianceID        = errors.New("no appliance ID")
+	ErrDuplicateApplianceID = errors.New("duplicate appliance ID")
+)
+
+// ApplianceHandler interface handles the control and query requests of an
+// appliance. The requests it gets are for actions the appliance supports.
+type ApplianceHandler interface {
+	ServeAppliance(ctx context.Context, message *RequestMessage) (*ResponseMessage, error)
+}
+
+// ApplianceHandlerFunc type
+type ApplianceHandlerFunc func(ctx context.Context, message *RequestMessage) (*ResponseMessage, error)
+
+// ServeAppliance method for implementing ApplianceHandler interface
+func (f ApplianceHandlerFunc) ServeAppliance(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	return f(ctx, message)
+}
+
+// RegisteredAppliance type
+type RegisteredAppliance struct {
+	Appliance *DiscoveredAppliance
+	Handler   ApplianceHandler
+}
+
+// Supports method reports whether the appliance supports the action
+func (a *DiscoveredAppliance) Supports(action Action) bool {
+	for _, supported := range a.Actions {
+		if supported == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplianceRegistry interface holds the appliances of the users. The user ID
+// is the ID of the user linked to the access token of the request, or an
+// empty string when the extension does not link accounts.
+type ApplianceRegistry interface {
+	Register(ctx context.Context, userID string, appliance *RegisteredAppliance) error
+	Unregister(ctx context.Context, userID, applianceID string) error
+	// Appliance returns nil without an error when the user has no appliance
+	// with the ID
+	Appliance(ctx context.Context, userID, applianceID string) (*RegisteredAppliance, error)
+	Appliances(ctx context.Context, userID string) ([]*RegisteredAppliance, error)
+}
+
+// MemoryApplianceRegistry type
+type MemoryApplianceRegistry struct {
+	mu         sync.Mutex
+	appliances map[string]map[string]*RegisteredAppliance
+}
+
+// NewMemoryApplianceRegistry function
+func NewMemoryApplianceRegistry() *MemoryApplianceRegistry {
+	return &MemoryApplianceRegistry{
+		appliances: map[string]map[string]*RegisteredAppliance{},
+	}
+}
+
+// Register method for implementing ApplianceRegistry interface
+func (r *MemoryApplianceRegistry) Register(ctx context.Context, userID string, appliance *RegisteredAppliance) error {
+	if appliance.Appliance == nil || appliance.Appliance.ApplianceID == "" {
+		return ErrNoApplianceID
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appliances, ok := r.appliances[userID]
+	if !ok {
+		appliances = map[string]*RegisteredAppliance{}
+		r.appliances[userID] = appliances
+	}
+	if _, ok := appliances[appliance.Appliance.ApplianceID]; ok {
+		return ErrDuplicateApplianceID
+	}
+	appliances[appliance.Appliance.ApplianceID] = appliance
+	return nil
+}
+
+// Unregister method for implementing ApplianceRegistry interface
+func (r *MemoryApplianceRegistry) Unregister(ctx context.Context, userID, applianceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.appliances[userID], applianceID)
+	return nil
+}
+
+// Appliance method for implementing ApplianceRegistry interface
+func (r *MemoryApplianceRegistry) Appliance(ctx context.Context, userID, applianceID string) (*RegisteredAppliance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.appliances[userID][applianceID], nil
+}
+
+// Appliances method for implementing ApplianceRegistry interface. The
+// appliances are sorted by ID.
+func (r *MemoryApplianceRegistry) Appliances(ctx context.Context, userID string) ([]*RegisteredAppliance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appliances := make([]*RegisteredAppliance, 0, len(r.appliances[userID]))
+	for _, appliance := range r.appliances[userID] {
+		appliances = append(appliances, appliance)
+	}
+	sort.Slice(appliances, func(i, j int) bool {
+		return appliances[i].Appliance.ApplianceID < appliances[j].Appliance.ApplianceID
+	})
+	return appliances, nil
+}
+
+// RegistryHandler type answers Clova Home requests from a registry. Discovery
+// requests get the appliances of the user, and health checks are answered as
+// healthy. Control and query requests are dispatched to the handler of the
+// appliance, after replying with the error response when the appliance is
+// unknown, unreachable or does not support the action.
+type RegistryHandler struct {
+	Registry ApplianceRegistry
+	// Resolver resolves the access tokens into the user IDs of the registry.
+	// All requests use the empty user ID when it is nil.
+	Resolver cek.TokenResolver
+}
+
+// NewRegistryHandler function
+func NewRegistryHandler(registry ApplianceRegistry, resolver cek.TokenResolver) *RegistryHandler {
+	return &RegistryHandler{
+		Registry: registry,
+		Resolver: resolver,
+	}
+}
+
+// ServeClovaHome method for implementing Handler interface
+func (h *RegistryHandler) ServeClovaHome(ctx context.Context, message *RequestMessage) (*ResponseMessage, error) {
+	if _, ok := message.Payload.(*HealthCheckRequest); ok {
+		return message.Respond(&HealthCheckResponse{Description: "OK", IsHealthy: true}), nil
+	}
+	userID, errorResponse, err := h.resolveUser(ctx, message)
+	if errorResponse != nil || err != nil {
+		return errorResponse, err
+	}
+
+	if _, ok := message.Payload.(*DiscoverAppliancesRequest); ok {
+		appliances, err := h.Registry.Appliances(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		discovered := make([]*DiscoveredAppliance, len(appliances))
+		for i, appliance := range appliances {
+			discovered[i] = appliance.Appliance
+		}
+		return message.Respond(&DiscoverAppliancesResponse{DiscoveredAppliances: discovered}), nil
+	}
+
+	request := message.ApplianceRequest()
+	if request == nil || request.Appliance == nil {
+		return message.Error(ErrorNoSuchTarget, nil), nil
+	}
+	appliance, err := h.Registry.Appliance(ctx, userID, request.Appliance.ApplianceID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case appliance == nil:
+		return message.Error(ErrorNoSuchTarget, nil), nil
+	case !appliance.Appliance.Supports(message.Action()) || appliance.Handler == nil:
+		return message.Error(ErrorUnsupportedOperation, nil), nil
+	case !appliance.Appliance.IsReachable:
+		return message.Error(ErrorTargetOffline, nil), nil
+	}
+	return appliance.Handler.ServeAppliance(ctx, message)
+}
+
+func (h *RegistryHandler) resolveUser(ctx context.Context, message *RequestMessage) (string, *ResponseMessage, error) {
+	if h.Resolver == nil {
+		return "", nil, nil
+	}
+	accessToken := message.AccessToken()
+	if accessToken == "" {
+		return "", message.Error(ErrorInvalidAccessToken, nil), nil
+	}
+	user, err := cek.ResolveLinkedUser(ctx, h.Resolver, accessToken)
+	switch {
+	case errors.Is(err, cek.ErrAccessTokenExpired):
+		return "", message.Error(ErrorExpiredAccessToken, nil), nil
+	case errors.Is(err, cek.ErrAccessTokenInvalid):
+		return "", message.Error(ErrorInvalidAccessToken, nil), nil
+	case err != nil:
+		return "", nil, err
+	}
+	return user.ID, nil, nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package clovahome_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+	"github.com/line/clova-cek-sdk-go/clovahome"
+)
+
+func testLight(id string, reachable bool) *clovahome.DiscoveredAppliance {
+	return &clovahome.DiscoveredAppliance{
+		ApplianceID:      id,
+		ManufacturerName: "LINE",
+		ModelName:        "Light",
+		Version:          "1.0",
+		FriendlyName:     "リビングの照明",
+		IsReachable:      reachable,
+		Actions:          []clovahome.Action{clovahome.ActionTurnOn, clovahome.ActionTurnOff},
+		ApplianceTypes:   []clovahome.ApplianceType{clovahome.ApplianceTypeLight},
+	}
+}
+
+func TestMemoryApplianceRegistry(t *testing.T) {
+	ctx := context.Background()
+	registry := clovahome.NewMemoryApplianceRegistry()
+	if err := registry.Register(ctx, "U1", &clovahome.RegisteredAppliance{Appliance: testLight("light-2", true)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(ctx, "U1", &clovahome.RegisteredAppliance{Appliance: testLight("light-1", true)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(ctx, "U1", &clovahome.RegisteredAppliance{Appliance: testLight("light-1", true)}); !errors.Is(err, clovahome.ErrDuplicateApplianceID) {
+		t.Errorf("Register duplicate: %v; want %v", err, clovahome.ErrDuplicateApplianceID)
+	}
+	if err := registry.Register(ctx, "U1", &clovahome.RegisteredAppliance{Appliance: &clovahome.DiscoveredAppliance{}}); !errors.Is(err, clovahome.ErrNoApplianceID) {
+		t.Errorf("Register without ID: %v; want %v", err, clovahome.ErrNoApplianceID)
+	}
+
+	appliances, _ := registry.Appliances(ctx, "U1")
+	if len(appliances) != 2 || appliances[0].Appliance.ApplianceID != "light-1" {
+		t.Errorf("Appliances: %v", appliances)
+	}
+	if appliances, _ := registry.Appliances(ctx, "U2"); len(appliances) != 0 {
+		t.Errorf("Appliances of U2: %v", appliances)
+	}
+	registry.Unregister(ctx, "U1", "light-1")
+	if appliance, _ := registry.Appliance(ctx, "U1", "light-1"); appliance != nil {
+		t.Errorf("Appliance after Unregister: %v", appliance)
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	ctx := context.Background()
+	registry := clovahome.NewMemoryApplianceRegistry()
+	turnedOn := 0
+	handler := clovahome.ApplianceHandlerFunc(func(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+		turnedOn++
+		return message.Respond(&clovahome.Confirmation{}), nil
+	})
+	registry.Register(ctx, "user-valid", &clovahome.RegisteredAppliance{Appliance: testLight("light-1", true), Handler: handler})
+	registry.Register(ctx, "user-valid", &clovahome.RegisteredAppliance{Appliance: testLight("light-2", false), Handler: handler})
+	ext := clovahome.NewExtension(clovahome.WithDebugMode,
+		clovahome.WithHandler(clovahome.NewRegistryHandler(registry, cek.TokenResolverFunc(func(ctx context.Context, accessToken string) (*cek.LinkedUser, error) {
+			switch accessToken {
+			case "expired":
+				return nil, cek.ErrAccessTokenExpired
+			case "valid":
+				return &cek.LinkedUser{ID: "user-valid"}, nil
+			case "nouser":
+				return nil, nil
+			}
+			return nil, cek.ErrAccessTokenInvalid
+		}))))
+
+	appliance := func(id string) string {
+		return `{"accessToken": "valid", "appliance": {"applianceId": "` + id + `"}}`
+	}
+	testCases := []struct {
+		name     string
+		payload  string
+		wantName string
+	}{
+		{name: "HealthCheckRequest", payload: `{}`, wantName: "HealthCheckResponse"},
+		{name: "DiscoverAppliancesRequest", payload: `{"accessToken": "valid"}`, wantName: "DiscoverAppliancesResponse"},
+		{name: "DiscoverAppliancesRequest", payload: `{"accessToken": "expired"}`, wantName: "ExpiredAccessTokenError"},
+		{name: "DiscoverAppliancesRequest", payload: `{"accessToken": "forged"}`, wantName: "InvalidAccessTokenError"},
+		{name: "DiscoverAppliancesRequest", payload: `{"accessToken": "nouser"}`, wantName: "InvalidAccessTokenError"},
+		{name: "DiscoverAppliancesRequest", payload: `{}`, wantName: "InvalidAccessTokenError"},
+		{name: "TurnOnRequest", payload: appliance("light-1"), wantName: "TurnOnConfirmation"},
+		{name: "TurnOnRequest", payload: appliance("light-3"), wantName: "NoSuchTargetError"},
+		{name: "TurnOnRequest", payload: appliance("light-2"), wantName: "TargetOfflineError"},
+		{name: "SetBrightnessRequest", payload: appliance("light-1"), wantName: "UnsupportedOperationError"},
+	}
+	for i, testCase := range testCases {
+		w := httptest.NewRecorder()
+		ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBody(testCase.name, testCase.payload))))
+		response := struct {
+			Header  *clovahome.Header `json:"header"`
+			Payload json.RawMessage   `json:"payload"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Response %d: %v: %s", i, err, w.Body.String())
+		}
+		if response.Header.Name != testCase.wantName {
+			t.Errorf("Response %d: %s; want %s", i, response.Header.Name, testCase.wantName)
+		}
+		if testCase.wantName == "DiscoverAppliancesResponse" && !strings.Contains(string(response.Payload), `"applianceId":"light-2"`) {
+			t.Errorf("Discovered appliances: %s", response.Payload)
+		}
+	}
+	if turnedOn != 1 {
+		t.Errorf("Handler calls: %d; want 1", turnedOn)
+	}
+}
+
+func TestServeHTTPHandlerError(t *testing.T) {
+	testCases := []struct {
+		err     error
+		wantLog string
+	}{
+		{err: errors.New("device cloud unavailable"), wantLog: "device cloud unavailable"},
+		{err: nil, wantLog: clovahome.ErrNoResponse.Error()},
+	}
+	for _, testCase := range testCases {
+		log := &bytes.Buffer{}
+		ext := clovahome.NewExtension(clovahome.WithDebugMode,
+			clovahome.WithLogger(slog.New(slog.NewTextHandler(log, nil))),
+			clovahome.WithHandler(clovahome.HandlerFunc(func(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+				return nil, testCase.err
+			})))
+		w := httptest.NewRecorder()
+		ext.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(testRequestBody("TurnOnRequest", `{}`))))
+		if w.Code != 200 || !strings.Contains(w.Body.String(), `"name":"DriverInternalError"`) {
+			t.Errorf("Response for %v: %d %s", testCase.err, w.Code, w.Body.String())
+		}
+		if !strings.Contains(log.String(), testCase.wantLog) {
+			t.Errorf("Log for %v: %s; want %q", testCase.err, log.String(), testCase.wantLog)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package simulator
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/line/clova-cek-sdk-go/clovahome"
+)
+
+// State type is the state of a virtual appliance. Only the members the
+// appliance has are used.
+type State struct {
+	On                 bool
+	Brightness         float64
+	TargetTemperature  float64
+	CurrentTemperature float64
+	Channel            float64
+	Volume             float64
+}
+
+// Appliance interface is a virtual appliance. It serves the requests of the
+// actions it supports and changes its state like the real appliance would.
+type Appliance interface {
+	clovahome.ApplianceHandler
+	// Describe returns the appliance as reported by discovery
+	Describe() *clovahome.DiscoveredAppliance
+	State() State
+	SetReachable(reachable bool)
+}
+
+// appliance holds the members common to the virtual appliances
+type appliance struct {
+	mu    sync.Mutex
+	info  *clovahome.DiscoveredAppliance
+	state State
+	// changed is called with the new description when it changes
+	changed func(info *clovahome.DiscoveredAppliance)
+}
+
+func newAppliance(id, name, model string, applianceType clovahome.ApplianceType, actions ...clovahome.Action) appliance {
+	return appliance{
+		info: &clovahome.DiscoveredAppliance{
+			ApplianceID:         id,
+			ManufacturerName:    "Simulator",
+			ModelName:           model,
+			Version:             "1.0",
+			FriendlyName:        name,
+			FriendlyDescription: "virtual " + strings.ToLower(model),
+			IsReachable:         true,
+			Actions:             append([]clovahome.Action{clovahome.ActionTurnOn, clovahome.ActionTurnOff}, actions...),
+			ApplianceTypes:      []clovahome.ApplianceType{applianceType},
+		},
+	}
+}
+
+// Describe method for implementing Appliance interface. It returns a copy, so
+// that the registry does not share it with SetReachable.
+func (a *appliance) Describe() *clovahome.DiscoveredAppliance {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	info := *a.info
+	return &info
+}
+
+// State method for implementing Appliance interface
+func (a *appliance) State() State {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.state
+}
+
+// SetReachable method for implementing Appliance interface
+func (a *appliance) SetReachable(reachable bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.info.IsReachable = reachable
+	if a.changed != nil {
+		info := *a.info
+		a.changed(&info)
+	}
+}
+
+// watch method sets the function called when the description changes
+func (a *appliance) watch(changed func(info *clovahome.DiscoveredAppliance)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.changed = changed
+}
+
+// power serves TurnOn and TurnOff, and reports whether the action is one of
+// them
+func (a *appliance) power(message *clovahome.RequestMessage) (*clovahome.ResponseMessage, bool) {
+	switch message.Action() {
+	case clovahome.ActionTurnOn:
+		a.state.On = true
+	case clovahome.ActionTurnOff:
+		a.state.On = false
+	default:
+		return nil, false
+	}
+	return message.Respond(&clovahome.Confirmation{}), true
+}
+
+// adjust returns the value requested by a Set, Increment or Decrement action
+// from the current value, or the error response when it is not within
+// [min, max]. Increments and decrements without a delta use 1.
+func adjust(message *clovahome.RequestMessage, current float64, set, delta *clovahome.Value, min, max float64) (float64, *clovahome.ResponseMessage) {
+	action := string(message.Action())
+	d := 1.0
+	if delta != nil {
+		d = delta.Value
+	}
+	var v float64
+	switch {
+	case strings.HasPrefix(action, "Set"):
+		if set == nil {
+			return 0, message.ValueOutOfRange(min, max)
+		}
+		v = set.Value
+	case strings.HasPrefix(action, "Increment"):
+		v = current + d
+	case strings.HasPrefix(action, "Decrement"):
+		v = current - d
+	}
+	if v < min || v > max {
+		return 0, message.ValueOutOfRange(min, max)
+	}
+	return v, nil
+}
+
+// Plug type is a virtual smart plug
+type Plug struct {
+	appliance
+}
+
+// NewPlug function
+func NewPlug(id, name string) *Plug {
+	return &Plug{
+		appliance: newAppliance(id, name, "Plug", clovahome.ApplianceTypeSmartPlug),
+	}
+}
+
+// ServeAppliance method for implementing clovahome.ApplianceHandler interface
+func (p *Plug) ServeAppliance(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if response, ok := p.power(message); ok {
+		return response, nil
+	}
+	return message.Error(clovahome.ErrorUnsupportedOperation, nil), nil
+}
+
+// Light type is a virtual dimmable light. The brightness is from 0 to 100.
+type Light struct {
+	appliance
+}
+
+// NewLight function returns a light switched off at full brightness
+func NewLight(id, name string) *Light {
+	l := &Light{
+		appliance: newAppliance(id, name, "Light", clovahome.ApplianceTypeLight,
+			clovahome.ActionSetBrightness, clovahome.ActionIncrementBrightness, clovahome.ActionDecrementBrightness),
+	}
+	l.state.Brightness = 100
+	return l
+}
+
+// ServeAppliance method for implementing clovahome.ApplianceHandler interface
+func (l *Light) ServeAppliance(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if response, ok := l.power(message); ok {
+		return response, nil
+	}
+	switch message.Action() {
+	case clovahome.ActionSetBrightness, clovahome.ActionIncrementBrightness, clovahome.ActionDecrementBrightness:
+		request := message.ApplianceRequest()
+		v, errorResponse := adjust(message, l.state.Brightness, request.Brightness, request.DeltaBrightness, 0, 100)
+		if errorResponse != nil {
+			return errorResponse, nil
+		}
+		previous := l.state.Brightness
+		l.state.Brightness = v
+		return message.Respond(&clovahome.Confirmation{
+			Brightness:    clovahome.NewValue(v),
+			PreviousState: &clovahome.State{Brightness: clovahome.NewValue(previous)},
+		}), nil
+	}
+	return message.Error(clovahome.ErrorUnsupportedOperation, nil), nil
+}
+
+// Thermostat type is a virtual air conditioner. The target temperature is
+// within [MinTemperature, MaxTemperature].
+type Thermostat struct {
+	appliance
+	MinTemperature float64
+	MaxTemperature float64
+}
+
+// NewThermostat function returns a thermostat switched off, targeting the
+// current temperature of the room
+func NewThermostat(id, name string, currentTemperature float64) *Thermostat {
+	t := &Thermostat{
+		appliance: newAppliance(id, name, "Thermostat", clovahome.ApplianceTypeAirConditioner,
+			clovahome.ActionSetTargetTemperature, clovahome.ActionIncrementTargetTemperature, clovahome.ActionDecrementTargetTemperature,
+			clovahome.ActionGetCurrentTemperature),
+		MinTemperature: 16,
+		MaxTemperature: 30,
+	}
+	t.state.CurrentTemperature = currentTemperature
+	t.state.TargetTemperature = currentTemperature
+	return t
+}
+
+// ServeAppliance method for implementing clovahome.ApplianceHandler interface
+func (t *Thermostat) ServeAppliance(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if response, ok := t.power(message); ok {
+		return response, nil
+	}
+	switch message.Action() {
+	case clovahome.ActionGetCurrentTemperature:
+		return message.Respond(&clovahome.Confirmation{
+			CurrentTemperature: clovahome.NewValue(t.state.CurrentTemperature),
+		}), nil
+	case clovahome.ActionSetTargetTemperature, clovahome.ActionIncrementTargetTemperature, clovahome.ActionDecrementTargetTemperature:
+		request := message.ApplianceRequest()
+		v, errorResponse := adjust(message, t.state.TargetTemperature, request.TargetTemperature, request.DeltaTemperature,
+			t.MinTemperature, t.MaxTemperature)
+		if errorResponse != nil {
+			return errorResponse, nil
+		}
+		previous := t.state.TargetTemperature
+		t.state.TargetTemperature = v
+		return message.Respond(&clovahome.Confirmation{
+			TargetTemperature: clovahome.NewValue(v),
+			PreviousState:     &clovahome.State{TargetTemperature: clovahome.NewValue(previous)},
+		}), nil
+	}
+	return message.Error(clovahome.ErrorUnsupportedOperation, nil), nil
+}
+
+// TV type is a virtual television. Channels are from 1 to MaxChannel and the
+// volume from 0 to MaxVolume. Changing them needs the TV to be on.
+type TV struct {
+	appliance
+	MaxChannel float64
+	MaxVolume  float64
+}
+
+// NewTV function returns a TV switched off on channel 1
+func NewTV(id, name string) *TV {
+	tv := &TV{
+		appliance: newAppliance(id, name, "TV", clovahome.ApplianceTypeSmartTV,
+			clovahome.ActionSetChannel, clovahome.ActionIncrementChannel, clovahome.ActionDecrementChannel,
+			clovahome.ActionSetVolume, clovahome.ActionIncrementVolume, clovahome.ActionDecrementVolume),
+		MaxChannel: 12,
+		MaxVolume:  50,
+	}
+	tv.state.Channel = 1
+	tv.state.Volume = 10
+	return tv
+}
+
+// ServeAppliance method for implementing clovahome.ApplianceHandler interface
+func (tv *TV) ServeAppliance(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+	if response, ok := tv.power(message); ok {
+		return response, nil
+	}
+	request := message.ApplianceRequest()
+	switch message.Action() {
+	case clovahome.ActionSetChannel, clovahome.ActionIncrementChannel, clovahome.ActionDecrementChannel:
+		if !tv.state.On {
+			return message.Error(clovahome.ErrorConditionsNotMet, nil), nil
+		}
+		v, errorResponse := adjust(message, tv.state.Channel, request.Channel, request.DeltaChannel, 1, tv.MaxChannel)
+		if errorResponse != nil {
+			return errorResponse, nil
+		}
+		previous := tv.state.Channel
+		tv.state.Channel = v
+		return message.Respond(&clovahome.Confirmation{
+			Channel:       clovahome.NewValue(v),
+			PreviousState: &clovahome.State{Channel: clovahome.NewValue(previous)},
+		}), nil
+	case clovahome.ActionSetVolume, clovahome.ActionIncrementVolume, clovahome.ActionDecrementVolume:
+		if !tv.state.On {
+			return message.Error(clovahome.ErrorConditionsNotMet, nil), nil
+		}
+		v, errorResponse := adjust(message, tv.state.Volume, request.Volume, request.DeltaVolume, 0, tv.MaxVolume)
+		if errorResponse != nil {
+			return errorResponse, nil
+		}
+		previous := tv.state.Volume
+		tv.state.Volume = v
+		return message.Respond(&clovahome.Confirmation{
+			Volume:        clovahome.NewValue(v),
+			PreviousState: &clovahome.State{Volume: clovahome.NewValue(previous)},
+		}), nil
+	}
+	return message.Error(clovahome.ErrorUnsupportedOperation, nil), nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package simulator runs Clova Home handlers against virtual appliances, so
+// that smart home integrations can be tested without real devices.
+//
+// The virtual lights, plugs, thermostats and TVs keep their state and answer
+// the requests of the actions they support like real appliances. A Simulator
+// registers them in a registry, sends the requests built with the clovahome
+// package to the handler under test and checks the resulting states:
+//
+//	light := simulator.NewLight("light-1", "リビングの照明")
+//	sim := simulator.New(light)
+//	sim.Handler = newHandler(sim.Registry)
+//	sim.AssertTransition(t, clovahome.RequestTurnOn, "light-1", nil, simulator.State{On: true, Brightness: 100})
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/clovahome"
+)
+
+// Simulator type
+type Simulator struct {
+	// Registry holds the appliances for UserID
+	Registry *clovahome.MemoryApplianceRegistry
+	// Handler is the handler under test. It is a RegistryHandler over
+	// Registry by default.
+	Handler clovahome.Handler
+	// AccessToken is put into the requests
+	AccessToken string
+	// UserID is the user the appliances are registered for
+	UserID     string
+	appliances map[string]Appliance
+}
+
+// New function returns a simulator with the appliances registered for the
+// empty user ID
+func New(appliances ...Appliance) *Simulator {
+	s := &Simulator{
+		Registry:   clovahome.NewMemoryApplianceRegistry(),
+		appliances: map[string]Appliance{},
+	}
+	s.Handler = clovahome.NewRegistryHandler(s.Registry, nil)
+	for _, appliance := range appliances {
+		if err := s.Add(appliance); err != nil {
+			panic(err)
+		}
+	}
+	return s
+}
+
+// Add method registers the appliance for UserID. The virtual appliances of
+// this package are registered again when SetReachable changes them.
+func (s *Simulator) Add(appliance Appliance) error {
+	info := appliance.Describe()
+	if err := s.Registry.Register(context.Background(), s.UserID, &clovahome.RegisteredAppliance{
+		Appliance: info,
+		Handler:   appliance,
+	}); err != nil {
+		return err
+	}
+	s.appliances[info.ApplianceID] = appliance
+	if w, ok := appliance.(watcher); ok {
+		userID := s.UserID
+		w.watch(func(info *clovahome.DiscoveredAppliance) {
+			ctx := context.Background()
+			s.Registry.Unregister(ctx, userID, info.ApplianceID)
+			s.Registry.Register(ctx, userID, &clovahome.RegisteredAppliance{
+				Appliance: info,
+				Handler:   appliance,
+			})
+		})
+	}
+	return nil
+}
+
+// watcher interface is implemented by the appliances reporting the changes
+// of their description
+type watcher interface {
+	watch(changed func(info *clovahome.DiscoveredAppliance))
+}
+
+// Appliance method returns the appliance with the ID, or nil
+func (s *Simulator) Appliance(applianceID string) Appliance {
+	return s.appliances[applianceID]
+}
+
+// Send method sends the message to the handler. The message goes through JSON
+// like a request of the platform.
+func (s *Simulator) Send(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	decoded := &clovahome.RequestMessage{}
+	if err := json.Unmarshal(b, decoded); err != nil {
+		return nil, err
+	}
+	return s.Handler.ServeClovaHome(ctx, decoded)
+}
+
+// Discover method sends a discovery request and returns the discovered
+// appliances
+func (s *Simulator) Discover(ctx context.Context) ([]*clovahome.DiscoveredAppliance, error) {
+	response, err := s.Send(ctx, clovahome.NewRequest(clovahome.RequestDiscoverAppliances,
+		&clovahome.DiscoverAppliancesRequest{AccessToken: s.AccessToken}))
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := response.Payload.(*clovahome.DiscoverAppliancesResponse)
+	if !ok {
+		return nil, &ResponseError{Name: response.Header.Name}
+	}
+	return payload.DiscoveredAppliances, nil
+}
+
+// Control method sends a control or query request for the appliance
+func (s *Simulator) Control(ctx context.Context, name, applianceID string, value *clovahome.Value) (*clovahome.ResponseMessage, error) {
+	return s.Send(ctx, clovahome.NewApplianceRequest(name, s.AccessToken, applianceID, value))
+}
+
+// ResponseError type is returned when the response is an error message
+type ResponseError struct {
+	Name string
+}
+
+// Error method for implementing error interface
+func (e *ResponseError) Error() string {
+	return "error response " + e.Name
+}
+
+// AssertTransition method sends the request and fails the test unless it is
+// confirmed and the appliance ends in the state want
+func (s *Simulator) AssertTransition(t testing.TB, name, applianceID string, value *clovahome.Value, want State) *clovahome.ResponseMessage {
+	t.Helper()
+	response, err := s.Control(context.Background(), name, applianceID, value)
+	if err != nil {
+		t.Errorf("%s %s: %v", name, applianceID, err)
+		return nil
+	}
+	if IsError(response) {
+		t.Errorf("%s %s: %s", name, applianceID, response.Header.Name)
+	}
+	s.assertState(t, name, applianceID, want)
+	return response
+}
+
+// AssertError method sends the request and fails the test unless it is
+// answered with the error message and the appliance keeps its state
+func (s *Simulator) AssertError(t testing.TB, name, applianceID string, value *clovahome.Value, want clovahome.ErrorName) *clovahome.ResponseMessage {
+	t.Helper()
+	var before State
+	if appliance := s.appliances[applianceID]; appliance != nil {
+		before = appliance.State()
+	}
+	response, err := s.Control(context.Background(), name, applianceID, value)
+	if err != nil {
+		t.Errorf("%s %s: %v", name, applianceID, err)
+		return nil
+	}
+	if response.Header.Name != string(want) {
+		t.Errorf("%s %s: %s; want %s", name, applianceID, response.Header.Name, want)
+	}
+	if s.appliances[applianceID] != nil {
+		s.assertState(t, name, applianceID, before)
+	}
+	return response
+}
+
+func (s *Simulator) assertState(t testing.TB, name, applianceID string, want State) {
+	t.Helper()
+	appliance := s.appliances[applianceID]
+	if appliance == nil {
+		t.Errorf("%s: no appliance %s", name, applianceID)
+		return
+	}
+	if got := appliance.State(); got != want {
+		t.Errorf("%s %s: state %+v; want %+v", name, applianceID, got, want)
+	}
+}
+
+// IsError function reports whether the response is an error message
+func IsError(response *clovahome.ResponseMessage) bool {
+	return strings.HasSuffix(response.Header.Name, "Error")
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package simulator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/clovahome"
+	"github.com/line/clova-cek-sdk-go/clovahome/simulator"
+)
+
+func TestLight(t *testing.T) {
+	sim := simulator.New(simulator.NewLight("light-1", "照明"))
+
+	sim.AssertTransition(t, clovahome.RequestTurnOn, "light-1", nil, simulator.State{On: true, Brightness: 100})
+	sim.AssertTransition(t, clovahome.RequestSetBrightness, "light-1", clovahome.NewValue(40), simulator.State{On: true, Brightness: 40})
+	sim.AssertTransition(t, clovahome.RequestIncrementBrightness, "light-1", clovahome.NewValue(20), simulator.State{On: true, Brightness: 60})
+	sim.AssertTransition(t, clovahome.RequestDecrementBrightness, "light-1", nil, simulator.State{On: true, Brightness: 59})
+	response := sim.AssertError(t, clovahome.RequestSetBrightness, "light-1", clovahome.NewValue(120), clovahome.ErrorValueOutOfRange)
+	payload, ok := response.Payload.(*clovahome.ValueOutOfRangeError)
+	if !ok || payload.MinimumValue != 0 || payload.MaximumValue != 100 {
+		t.Errorf("payload %+v", response.Payload)
+	}
+	sim.AssertError(t, clovahome.RequestSetVolume, "light-1", clovahome.NewValue(1), clovahome.ErrorUnsupportedOperation)
+	sim.AssertTransition(t, clovahome.RequestTurnOff, "light-1", nil, simulator.State{Brightness: 59})
+}
+
+func TestLightConfirmation(t *testing.T) {
+	sim := simulator.New(simulator.NewLight("light-1", "照明"))
+	response := sim.AssertTransition(t, clovahome.RequestSetBrightness, "light-1", clovahome.NewValue(30), simulator.State{Brightness: 30})
+	if response.Header.Name != "SetBrightnessConfirmation" {
+		t.Errorf("name %s", response.Header.Name)
+	}
+	confirmation, ok := response.Payload.(*clovahome.Confirmation)
+	if !ok || confirmation.Brightness.Value != 30 || confirmation.PreviousState.Brightness.Value != 100 {
+		t.Errorf("payload %+v", response.Payload)
+	}
+}
+
+func TestThermostat(t *testing.T) {
+	sim := simulator.New(simulator.NewThermostat("ac-1", "エアコン", 25))
+
+	sim.AssertTransition(t, clovahome.RequestSetTargetTemperature, "ac-1", clovahome.NewValue(22), simulator.State{TargetTemperature: 22, CurrentTemperature: 25})
+	sim.AssertTransition(t, clovahome.RequestIncrementTargetTemperature, "ac-1", nil, simulator.State{TargetTemperature: 23, CurrentTemperature: 25})
+	sim.AssertError(t, clovahome.RequestDecrementTargetTemperature, "ac-1", clovahome.NewValue(10), clovahome.ErrorValueOutOfRange)
+	response := sim.AssertTransition(t, clovahome.RequestGetCurrentTemperature, "ac-1", nil, simulator.State{TargetTemperature: 23, CurrentTemperature: 25})
+	if response.Header.Name != "GetCurrentTemperatureResponse" {
+		t.Errorf("name %s", response.Header.Name)
+	}
+	if confirmation, ok := response.Payload.(*clovahome.Confirmation); !ok || confirmation.CurrentTemperature.Value != 25 {
+		t.Errorf("payload %+v", response.Payload)
+	}
+}
+
+func TestTV(t *testing.T) {
+	sim := simulator.New(simulator.NewTV("tv-1", "テレビ"))
+
+	sim.AssertError(t, clovahome.RequestSetChannel, "tv-1", clovahome.NewValue(4), clovahome.ErrorConditionsNotMet)
+	sim.AssertTransition(t, clovahome.RequestTurnOn, "tv-1", nil, simulator.State{On: true, Channel: 1, Volume: 10})
+	sim.AssertTransition(t, clovahome.RequestSetChannel, "tv-1", clovahome.NewValue(4), simulator.State{On: true, Channel: 4, Volume: 10})
+	sim.AssertTransition(t, clovahome.RequestDecrementChannel, "tv-1", nil, simulator.State{On: true, Channel: 3, Volume: 10})
+	sim.AssertError(t, clovahome.RequestIncrementChannel, "tv-1", clovahome.NewValue(10), clovahome.ErrorValueOutOfRange)
+	sim.AssertTransition(t, clovahome.RequestIncrementVolume, "tv-1", clovahome.NewValue(5), simulator.State{On: true, Channel: 3, Volume: 15})
+	sim.AssertError(t, clovahome.RequestDecrementVolume, "tv-1", clovahome.NewValue(20), clovahome.ErrorValueOutOfRange)
+}
+
+func TestRegistry(t *testing.T) {
+	plug := simulator.NewPlug("plug-1", "プラグ")
+	sim := simulator.New(plug, simulator.NewLight("light-1", "照明"))
+
+	appliances, err := sim.Discover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(appliances) != 2 || appliances[0].ApplianceID != "light-1" || appliances[1].ApplianceID != "plug-1" {
+		t.Errorf("appliances %+v", appliances)
+	}
+
+	sim.AssertError(t, clovahome.RequestTurnOn, "unknown", nil, clovahome.ErrorNoSuchTarget)
+	sim.AssertError(t, clovahome.RequestSetBrightness, "plug-1", clovahome.NewValue(50), clovahome.ErrorUnsupportedOperation)
+	plug.SetReachable(false)
+	sim.AssertError(t, clovahome.RequestTurnOn, "plug-1", nil, clovahome.ErrorTargetOffline)
+	plug.SetReachable(true)
+	sim.AssertTransition(t, clovahome.RequestTurnOn, "plug-1", nil, simulator.State{On: true})
+
+	if err := sim.Add(simulator.NewPlug("plug-1", "プラグ")); err != clovahome.ErrDuplicateApplianceID {
+		t.Errorf("Add duplicate: %v", err)
+	}
+}
+
+func TestSetReachableConcurrently(t *testing.T) {
+	plug := simulator.NewPlug("plug-1", "プラグ")
+	sim := simulator.New(plug)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			plug.SetReachable(i%2 == 0)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if _, err := sim.Control(context.Background(), clovahome.RequestTurnOn, "plug-1", nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sim.Discover(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	appliances, err := sim.Discover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(appliances) != 1 || appliances[0].IsReachable {
+		t.Errorf("appliances %+v; want plug-1 unreachable", appliances)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	sim := simulator.New(simulator.NewPlug("plug-1", "プラグ"))
+	sim.AccessToken = "token"
+	var got string
+	sim.Handler = clovahome.HandlerFunc(func(ctx context.Context, message *clovahome.RequestMessage) (*clovahome.ResponseMessage, error) {
+		got = message.AccessToken()
+		return message.Error(clovahome.ErrorTargetHardwareMalfunction, nil), nil
+	})
+	sim.AssertError(t, clovahome.RequestTurnOn, "plug-1", nil, clovahome.ErrorTargetHardwareMalfunction)
+	if got != "token" {
+		t.Errorf("access token %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Command cekgen generates Go code from an exported interaction model: intent
+// name constants, slot structs per intent, string types for the custom slot
+// types and an interface with a method per intent.
+//
+// Usage:
+//
+//	cekgen -model model.json -package pizza -o model_gen.go
+//
+// It can be run by go generate:
+//
+//	//go:generate cekgen -model model.tsv -package pizza -o model_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/line/clova-cek-sdk-go/interactionmodel"
+)
+
+func main() {
+	modelPath := flag.String("model", "", "interaction model file (.json or .tsv)")
+	pkg := flag.String("package", "main", "package name of the generated code")
+	output := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if err := run(*modelPath, *pkg, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "cekgen: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(modelPath, pkg, output string) error {
+	if modelPath == "" {
+		return fmt.Errorf("-model is required")
+	}
+	m, err := interactionmodel.Load(modelPath)
+	if err != nil {
+		return err
+	}
+	src, err := interactionmodel.Generate(m, pkg)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(output, src, 0644)
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Command ceklint checks the Go sources of an extension against its exported
+// interaction model. It reports intents without a handler, handlers for
+// intents the model does not have, slots absent from the model and sample
+// utterances colliding across intents.
+//
+// Usage:
+//
+//	ceklint -model model.json [dir ...]
+//
+// The Go files of the directories, the current one by default, are linted
+// together. ceklint exits with status 1 when it reports issues.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/line/clova-cek-sdk-go/interactionmodel"
+)
+
+func main() {
+	modelPath := flag.String("model", "", "interaction model file (.json or .tsv)")
+	flag.Parse()
+
+	n, err := run(*modelPath, flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ceklint: %s\n", err.Error())
+		os.Exit(2)
+	}
+	if n > 0 {
+		os.Exit(1)
+	}
+}
+
+func run(modelPath string, dirs []string) (int, error) {
+	if modelPath == "" {
+		return 0, fmt.Errorf("-model is required")
+	}
+	m, err := interactionmodel.Load(modelPath)
+	if err != nil {
+		return 0, err
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	issues, err := interactionmodel.LintDir(m, dirs...)
+	if err != nil {
+		return 0, err
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	return len(issues), nil
+}
@@ -0,0 +1,300 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package interactionmodel
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Generate function returns Go source of the package pkg declaring for the
+// model:
+//
+//   - a constant per intent name, named by IntentConstName
+//   - a string type per custom slot type, with a constant per value and a
+//     Parse function accepting the synonyms. A synonym of two values is an
+//     error.
+//   - a slots struct per intent and its constructor from *cek.Intent
+//   - an IntentHandler interface with a method per intent, and
+//     RegisterIntentHandler to register it on a *cek.ServeMux
+//
+// Names of the model declaring the same identifier, like the intents
+// order_pizza and OrderPizza, are an error.
+func Generate(m *Model, pkg string) ([]byte, error) {
+	data := &generateData{Package: pkg}
+	// names maps the top-level identifiers to what they are declared for
+	names := map[string]string{}
+	declare := func(what string, idents ...string) error {
+		for _, ident := range idents {
+			if other, ok := names[ident]; ok {
+				return fmt.Errorf("%s and %s are both declared as %s", other, what, ident)
+			}
+			names[ident] = what
+		}
+		return nil
+	}
+	if err := declare("the intent handler", "IntentHandler", "RegisterIntentHandler"); err != nil {
+		return nil, err
+	}
+	for _, slotType := range m.SlotTypes {
+		t := &generateSlotType{Name: slotType.Name, GoName: GoName(slotType.Name)}
+		if err := declare("slot type "+slotType.Name, t.GoName, "Parse"+t.GoName); err != nil {
+			return nil, err
+		}
+		// values maps the values and synonyms to the value they parse to
+		values := map[string]string{}
+		for _, value := range slotType.Values {
+			var cases []string
+			for _, s := range append([]string{value.Value}, value.Synonyms...) {
+				if other, ok := values[s]; ok {
+					if other != value.Value {
+						return nil, fmt.Errorf("slot type %s: %q is a synonym of both %q and %q", slotType.Name, s, other, value.Value)
+					}
+					continue
+				}
+				values[s] = value.Value
+				cases = append(cases, strconv.Quote(s))
+			}
+			t.Values = append(t.Values, &generateSlotValue{
+				Value: value.Value,
+				Cases: strings.Join(cases, ", "),
+			})
+		}
+		data.SlotTypes = append(data.SlotTypes, t)
+	}
+	for _, intent := range m.Intents {
+		i := &generateIntent{
+			Name:   intent.Name,
+			GoName: GoName(intent.Name),
+			Const:  IntentConstName(intent.Name),
+		}
+		if err := declare("intent "+intent.Name, i.Const, i.GoName+"Slots", "New"+i.GoName+"Slots"); err != nil {
+			return nil, err
+		}
+		used := map[string]bool{}
+		for _, slot := range intent.Slots {
+			s := &generateSlot{
+				Name:      slot.Name,
+				Field:     uniqueName(GoName(slot.Name), used),
+				FieldType: "*cek.Slot",
+			}
+			if m.SlotType(slot.Type) != nil {
+				s.Parse = "Parse" + GoName(slot.Type)
+				s.FieldType = GoName(slot.Type)
+			}
+			i.Slots = append(i.Slots, s)
+		}
+		data.Intents = append(data.Intents, i)
+	}
+	// the value constants are numbered rather than failing on a clash, as
+	// values often differ only in characters dropped from the names
+	used := map[string]bool{}
+	for name := range names {
+		used[name] = true
+	}
+	for _, t := range data.SlotTypes {
+		for _, value := range t.Values {
+			suffix := camelCase(value.Value)
+			if suffix == "" {
+				suffix = "Value"
+			}
+			value.Const = uniqueName(t.GoName+suffix, used)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := generateTemplate.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid source: %s", err.Error())
+	}
+	return src, nil
+}
+
+// IntentConstName function returns the name of the constant Generate
+// declares for the intent name
+func IntentConstName(name string) string {
+	return "Intent" + GoName(name)
+}
+
+// GoName function turns a name of the model into an exported Go identifier.
+// The name is split at characters that are neither letters nor digits, and
+// the first letter of each part is upper-cased. Parts written in all upper
+// case ASCII, like PIZZA_TYPE, are title-cased. Names which do not start
+// with an upper-case letter then, like Japanese names, get an X prefix.
+func GoName(name string) string {
+	s := camelCase(name)
+	if r, _ := utf8.DecodeRuneInString(s); !unicode.IsUpper(r) {
+		s = "X" + s
+	}
+	return s
+}
+
+func camelCase(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, part := range parts {
+		if isUpperASCII(part) {
+			part = strings.ToLower(part)
+		}
+		r, size := utf8.DecodeRuneInString(part)
+		b.WriteRune(unicode.ToUpper(r))
+		b.WriteString(part[size:])
+	}
+	return b.String()
+}
+
+func isUpperASCII(s string) bool {
+	hasUpper := false
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' || r >= utf8.RuneSelf {
+			return false
+		}
+		if r >= 'A' && r <= 'Z' {
+			hasUpper = true
+		}
+	}
+	return hasUpper
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
+
+type generateData struct {
+	Package   string
+	Intents   []*generateIntent
+	SlotTypes []*generateSlotType
+}
+
+type generateIntent struct {
+	Name   string
+	GoName string
+	Const  string
+	Slots  []*generateSlot
+}
+
+type generateSlot struct {
+	Name      string
+	Field     string
+	FieldType string
+	Parse     string
+}
+
+type generateSlotType struct {
+	Name   string
+	GoName string
+	Values []*generateSlotValue
+}
+
+type generateSlotValue struct {
+	Const string
+	Value string
+	Cases string
+}
+
+var generateTemplate = template.Must(template.New("").Parse(`// Code generated by cekgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Intents}}
+	"context"
+{{end}}
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+// Intent names
+const (
+{{- range .Intents}}
+	{{.Const}} = {{printf "%q" .Name}}
+{{- end}}
+)
+{{range $t := .SlotTypes}}
+// {{.GoName}} type is the custom slot type {{.Name}}
+type {{.GoName}} string
+
+// {{.GoName}} constants
+const (
+{{- range .Values}}
+	{{.Const}} {{$t.GoName}} = {{printf "%q" .Value}}
+{{- end}}
+)
+
+// Parse{{.GoName}} function returns the value of {{.Name}} matching s or one of its synonyms
+func Parse{{.GoName}}(s string) ({{.GoName}}, bool) {
+	switch s {
+{{- range .Values}}{{if .Cases}}
+	case {{.Cases}}:
+		return {{.Const}}, true
+{{- end}}{{end}}
+	}
+	return "", false
+}
+{{end}}
+{{- range .Intents}}
+// {{.GoName}}Slots type holds the slots of {{.Name}}
+type {{.GoName}}Slots struct {
+{{- range .Slots}}
+	{{.Field}} {{.FieldType}}
+{{- end}}
+}
+
+// New{{.GoName}}Slots function
+func New{{.GoName}}Slots(intent *cek.Intent) *{{.GoName}}Slots {
+	slots := &{{.GoName}}Slots{}
+{{- range .Slots}}
+	if slot := intent.Slots[{{printf "%q" .Name}}]; slot != nil {
+{{- if .Parse}}
+		slots.{{.Field}}, _ = {{.Parse}}(slot.Value)
+{{- else}}
+		slots.{{.Field}} = slot
+{{- end}}
+	}
+{{- end}}
+	return slots
+}
+{{end}}
+// IntentHandler interface is implemented by the handlers of all intents of the model
+type IntentHandler interface {
+{{- range .Intents}}
+	{{.GoName}}(ctx context.Context, message *cek.RequestMessage, slots *{{.GoName}}Slots) (*cek.ResponseMessage, error)
+{{- end}}
+}
+
+// RegisterIntentHandler function registers h for all intents of the model
+func RegisterIntentHandler(mux *cek.ServeMux, h IntentHandler) {
+{{- range .Intents}}
+	mux.HandleIntent({{.Const}}, cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+		return h.{{.GoName}}(ctx, message, New{{.GoName}}Slots(message.Request.(*cek.IntentRequest).Intent))
+	}))
+{{- end}}
+}
+`))
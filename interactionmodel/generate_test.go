@@ -0,0 +1,168 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package interactionmodel_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/interactionmodel"
+)
+
+func TestGoName(t *testing.T) {
+	testCases := map[string]string{
+		"OrderPizza":        "OrderPizza",
+		"pizzaType":         "PizzaType",
+		"PIZZA_TYPE":        "PizzaType",
+		"Clova.GuideIntent": "ClovaGuideIntent",
+		"order-pizza 2":     "OrderPizza2",
+		"ペパロニ":              "Xペパロニ",
+		"3rd":               "X3rd",
+	}
+	for name, want := range testCases {
+		if got := interactionmodel.GoName(name); got != want {
+			t.Errorf("GoName(%q): %s; want %s", name, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	m, err := interactionmodel.Load(filepath.Join("testdata", "pizza.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := interactionmodel.Generate(m, "pizza")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "model_gen.go", src, 0); err != nil {
+		t.Fatal(err)
+	}
+	// compare ignoring the alignment made by gofmt
+	normalized := strings.Join(strings.Fields(string(src)), " ")
+	for _, want := range []string{
+		"package pizza\n",
+		`IntentOrderPizza       = "OrderPizza"`,
+		`IntentClovaGuideIntent = "Clova.GuideIntent"`,
+		"type PizzaType string\n",
+		`PizzaTypeペパロニ   PizzaType = "ペパロニ"`,
+		`PizzaTypeSeafood PizzaType = "seafood"`,
+		`case "ペパロニ", "ペパロニピザ", "ペペロニ":`,
+		"PizzaType PizzaType\n",
+		"When      *cek.Slot\n",
+		"OrderPizza(ctx context.Context, message *cek.RequestMessage, slots *OrderPizzaSlots) (*cek.ResponseMessage, error)\n",
+		"mux.HandleIntent(IntentCancelOrder, ",
+	} {
+		if !strings.Contains(normalized, strings.Join(strings.Fields(want), " ")) {
+			t.Errorf("Generated source does not contain %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateSynonyms(t *testing.T) {
+	m, err := interactionmodel.ParseTSV(strings.NewReader(strings.Join([]string{
+		"slot\tOrderPizza\tsize\tSIZE",
+		"value\tSIZE\tlarge\tlarge\tbig",
+		"value\tSIZE\thuge\tgigantic\tenormous\tgigantic",
+		"value\tSIZE\tlarge\tL",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := interactionmodel.Generate(m, "pizza")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGenerated(t, src)
+
+	m.SlotTypes[0].Values = append(m.SlotTypes[0].Values, &interactionmodel.SlotValue{Value: "huge", Synonyms: []string{"big"}})
+	if _, err := interactionmodel.Generate(m, "pizza"); err == nil || !strings.Contains(err.Error(), `"big"`) {
+		t.Errorf("Generate: %v; want an error for the synonym big", err)
+	}
+}
+
+func TestGenerateClashes(t *testing.T) {
+	testCases := []struct {
+		name  string
+		model *interactionmodel.Model
+		err   string
+	}{
+		{
+			name: "intents",
+			model: &interactionmodel.Model{Intents: []*interactionmodel.Intent{
+				{Name: "order_pizza"},
+				{Name: "OrderPizza"},
+			}},
+			err: "intent order_pizza and intent OrderPizza are both declared as IntentOrderPizza",
+		},
+		{
+			name: "slot type and intent",
+			model: &interactionmodel.Model{
+				Intents:   []*interactionmodel.Intent{{Name: "Order"}},
+				SlotTypes: []*interactionmodel.SlotType{{Name: "ORDER_SLOTS"}},
+			},
+			err: "slot type ORDER_SLOTS and intent Order are both declared as OrderSlots",
+		},
+		{
+			name: "slot types only",
+			model: &interactionmodel.Model{SlotTypes: []*interactionmodel.SlotType{
+				{Name: "SIZE", Values: []*interactionmodel.SlotValue{{Value: "large"}}},
+			}},
+		},
+		{
+			name: "value constants",
+			model: &interactionmodel.Model{
+				Intents: []*interactionmodel.Intent{{Name: "OrderPizza"}},
+				SlotTypes: []*interactionmodel.SlotType{
+					{Name: "Intent", Values: []*interactionmodel.SlotValue{{Value: "handler"}, {Value: "OrderPizza"}}},
+					{Name: "IntentHand", Values: []*interactionmodel.SlotValue{{Value: "ler"}}},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		src, err := interactionmodel.Generate(tc.model, "pizza")
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%s: Generate: %v; want %s", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Generate: %v", tc.name, err)
+			continue
+		}
+		checkGenerated(t, src)
+	}
+}
+
+func checkGenerated(t *testing.T, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model_gen.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := config.Check("pizza", fset, []*ast.File{file}, nil); err != nil {
+		t.Errorf("Generated source does not compile: %v\n%s", err, src)
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package interactionmodel
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+// IssueKind type
+type IssueKind string
+
+// IssueKind constants
+const (
+	IssueUncoveredIntent    IssueKind = "uncovered-intent"
+	IssueUnknownIntent      IssueKind = "unknown-intent"
+	IssueUnknownSlot        IssueKind = "unknown-slot"
+	IssueUtteranceCollision IssueKind = "utterance-collision"
+)
+
+// Issue type
+type Issue struct {
+	Kind IssueKind
+	// Pos is the position in the Go sources, if any
+	Pos     token.Position
+	Message string
+}
+
+// String method
+func (i *Issue) String() string {
+	if i.Pos.IsValid() {
+		return fmt.Sprintf("%s: %s (%s)", i.Pos, i.Message, i.Kind)
+	}
+	return fmt.Sprintf("%s (%s)", i.Message, i.Kind)
+}
+
+// builtinIntents maps the names of the built-in intent constants of cek to
+// their values. Built-in intents exist whether the model lists them or not.
+var builtinIntents = map[string]string{
+	"BuiltinIntentCancel":   cek.BuiltinIntentCancel,
+	"BuiltinIntentGuide":    cek.BuiltinIntentGuide,
+	"BuiltinIntentNext":     cek.BuiltinIntentNext,
+	"BuiltinIntentNo":       cek.BuiltinIntentNo,
+	"BuiltinIntentPause":    cek.BuiltinIntentPause,
+	"BuiltinIntentPrevious": cek.BuiltinIntentPrevious,
+	"BuiltinIntentResume":   cek.BuiltinIntentResume,
+	"BuiltinIntentStop":     cek.BuiltinIntentStop,
+	"BuiltinIntentYes":      cek.BuiltinIntentYes,
+}
+
+// LintDir function lints the Go files of the directories, except tests,
+// together against the model
+func LintDir(m *Model, dirs ...string) ([]*Issue, error) {
+	var sources []string
+	for _, dir := range dirs {
+		filenames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		for _, filename := range filenames {
+			if !strings.HasSuffix(filename, "_test.go") {
+				sources = append(sources, filename)
+			}
+		}
+	}
+	return Lint(m, sources...)
+}
+
+// Lint function checks the Go source files of an extension against the
+// model. It reports
+//
+//   - intents of the model no handler covers
+//   - handlers for intents the model does not have
+//   - slots referenced in the sources but absent from the intent, or from
+//     the whole model when the intent is not known
+//   - sample utterances colliding across intents
+//
+// An intent is covered when it is registered with ServeMux.HandleIntent, or
+// compared with an Intent.Name in a switch or an == expression. Intent names
+// are resolved from string literals, string constants declared in the files
+// and the built-in intent constants of cek.
+func Lint(m *Model, filenames ...string) ([]*Issue, error) {
+	l := &linter{
+		model:   m,
+		fset:    token.NewFileSet(),
+		consts:  map[string]string{},
+		handled: map[string]bool{},
+		seen:    map[token.Pos]bool{},
+	}
+	var files []*ast.File
+	for _, filename := range filenames {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(l.fset, filename, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	for _, f := range files {
+		l.collectConsts(f)
+	}
+	for _, f := range files {
+		l.collectHandlers(f)
+	}
+	for _, f := range files {
+		l.collectSlots(f, "")
+	}
+
+	var issues []*Issue
+	for _, intent := range m.Intents {
+		if !l.handled[intent.Name] {
+			issues = append(issues, &Issue{
+				Kind:    IssueUncoveredIntent,
+				Message: fmt.Sprintf("intent %s has no handler", intent.Name),
+			})
+		}
+	}
+	for _, ref := range l.handlers {
+		if m.Intent(ref.intent) == nil && !isBuiltinIntent(ref.intent) {
+			issues = append(issues, &Issue{
+				Kind:    IssueUnknownIntent,
+				Pos:     l.fset.Position(ref.pos),
+				Message: fmt.Sprintf("intent %s is not in the model", ref.intent),
+			})
+		}
+	}
+	sort.Slice(l.slots, func(i, j int) bool { return l.slots[i].pos < l.slots[j].pos })
+	for _, ref := range l.slots {
+		if issue := l.checkSlot(ref); issue != nil {
+			issues = append(issues, issue)
+		}
+	}
+	issues = append(issues, utteranceCollisions(m)...)
+	return issues, nil
+}
+
+type intentRef struct {
+	intent string
+	pos    token.Pos
+}
+
+type slotRef struct {
+	intent string
+	slot   string
+	pos    token.Pos
+}
+
+type linter struct {
+	model    *Model
+	fset     *token.FileSet
+	consts   map[string]string
+	handlers []*intentRef
+	handled  map[string]bool
+	slots    []*slotRef
+	seen     map[token.Pos]bool
+}
+
+func (l *linter) collectConsts(f *ast.File) {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			for i, name := range value.Names {
+				if i >= len(value.Values) {
+					break
+				}
+				if s, ok := stringLiteral(value.Values[i]); ok {
+					l.consts[name.Name] = s
+				}
+			}
+		}
+	}
+}
+
+func (l *linter) handle(intent string, pos token.Pos) {
+	l.handled[intent] = true
+	l.handlers = append(l.handlers, &intentRef{intent: intent, pos: pos})
+}
+
+func (l *linter) collectHandlers(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "HandleIntent" || len(n.Args) != 2 {
+				return true
+			}
+			if intent, ok := l.resolve(n.Args[0]); ok {
+				l.handle(intent, n.Args[0].Pos())
+				l.collectSlots(n.Args[1], intent)
+			}
+		case *ast.SwitchStmt:
+			if n.Tag == nil || !isIntentName(n.Tag) {
+				return true
+			}
+			for _, stmt := range n.Body.List {
+				clause := stmt.(*ast.CaseClause)
+				var intents []string
+				for _, expr := range clause.List {
+					if intent, ok := l.resolve(expr); ok {
+						l.handle(intent, expr.Pos())
+						intents = append(intents, intent)
+					}
+				}
+				if len(intents) == 1 {
+					for _, body := range clause.Body {
+						l.collectSlots(body, intents[0])
+					}
+				}
+			}
+		case *ast.BinaryExpr:
+			if n.Op != token.EQL {
+				return true
+			}
+			for _, pair := range [][2]ast.Expr{{n.X, n.Y}, {n.Y, n.X}} {
+				if isIntentName(pair[0]) {
+					if intent, ok := l.resolve(pair[1]); ok {
+						l.handle(intent, pair[1].Pos())
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// collectSlots records the slot names n refers to with index expressions on
+// Slots, attributing them to intent when it is not empty
+func (l *linter) collectSlots(n ast.Node, intent string) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		index, ok := n.(*ast.IndexExpr)
+		if !ok || l.seen[index.Pos()] {
+			return true
+		}
+		sel, ok := index.X.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Slots" {
+			return true
+		}
+		if slot, ok := l.resolve(index.Index); ok {
+			l.seen[index.Pos()] = true
+			l.slots = append(l.slots, &slotRef{intent: intent, slot: slot, pos: index.Pos()})
+		}
+		return true
+	})
+}
+
+func (l *linter) checkSlot(ref *slotRef) *Issue {
+	if ref.intent != "" {
+		intent := l.model.Intent(ref.intent)
+		if intent == nil || intent.Slot(ref.slot) != nil {
+			return nil
+		}
+		return &Issue{
+			Kind:    IssueUnknownSlot,
+			Pos:     l.fset.Position(ref.pos),
+			Message: fmt.Sprintf("slot %s is not in intent %s", ref.slot, ref.intent),
+		}
+	}
+	for _, intent := range l.model.Intents {
+		if intent.Slot(ref.slot) != nil {
+			return nil
+		}
+	}
+	return &Issue{
+		Kind:    IssueUnknownSlot,
+		Pos:     l.fset.Position(ref.pos),
+		Message: fmt.Sprintf("slot %s is not in the model", ref.slot),
+	}
+}
+
+func (l *linter) resolve(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return stringLiteral(expr)
+	case *ast.Ident:
+		s, ok := l.consts[expr.Name]
+		return s, ok
+	case *ast.SelectorExpr:
+		if s, ok := builtinIntents[expr.Sel.Name]; ok {
+			return s, true
+		}
+		s, ok := l.consts[expr.Sel.Name]
+		return s, ok
+	case *ast.ParenExpr:
+		return l.resolve(expr.X)
+	}
+	return "", false
+}
+
+// isIntentName reports whether expr has the form x.Intent.Name
+func isIntentName(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Name" {
+		return false
+	}
+	x, ok := sel.X.(*ast.SelectorExpr)
+	return ok && x.Sel.Name == "Intent"
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func isBuiltinIntent(name string) bool {
+	for _, builtin := range builtinIntents {
+		if builtin == name {
+			return true
+		}
+	}
+	return false
+}
+
+var slotPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+// utteranceCollisions reports sample utterances of different intents which
+// are the same once slots are replaced by their types, and case, spaces and
+// punctuation are ignored
+func utteranceCollisions(m *Model) []*Issue {
+	owners := map[string][]string{}
+	var keys []string
+	for _, intent := range m.Intents {
+		for _, utterance := range intent.SampleUtterances {
+			key := normalizeUtterance(intent, utterance)
+			if len(owners[key]) == 0 {
+				keys = append(keys, key)
+			}
+			owners[key] = append(owners[key], intent.Name+": "+utterance)
+		}
+	}
+	var issues []*Issue
+	for _, key := range keys {
+		intents := map[string]bool{}
+		for _, owner := range owners[key] {
+			intents[strings.SplitN(owner, ":", 2)[0]] = true
+		}
+		if len(intents) < 2 {
+			continue
+		}
+		utterances := owners[key]
+		sort.Strings(utterances)
+		issues = append(issues, &Issue{
+			Kind:    IssueUtteranceCollision,
+			Message: fmt.Sprintf("sample utterances collide: %s", strings.Join(utterances, " / ")),
+		})
+	}
+	return issues
+}
+
+func normalizeUtterance(intent *Intent, utterance string) string {
+	utterance = slotPattern.ReplaceAllStringFunc(utterance, func(s string) string {
+		name := s[1 : len(s)-1]
+		if slot := intent.Slot(name); slot != nil {
+			return "{" + slot.Type + "}"
+		}
+		return s
+	})
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '{' && r != '}') {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, utterance)
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package interactionmodel_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/interactionmodel"
+)
+
+func lintMessages(issues []*interactionmodel.Issue) []string {
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, string(issue.Kind)+": "+issue.Message)
+	}
+	return messages
+}
+
+func TestLintDir(t *testing.T) {
+	m, err := interactionmodel.Load(filepath.Join("testdata", "pizza.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := interactionmodel.LintDir(m, filepath.Join("testdata", "lint"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"unknown-intent: intent TrackOrder is not in the model",
+		"unknown-slot: slot size is not in intent OrderPizza",
+		"unknown-slot: slot orderNumber is not in the model",
+		"unknown-slot: slot pizzaType is not in intent CancelOrder",
+	}
+	if got := lintMessages(issues); !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, issue := range issues {
+		if !issue.Pos.IsValid() || filepath.Base(issue.Pos.Filename) != "handlers.go" {
+			t.Errorf("%s: position not in handlers.go", issue)
+		}
+	}
+}
+
+func TestLintUncoveredIntents(t *testing.T) {
+	m, err := interactionmodel.Load(filepath.Join("testdata", "pizza.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := interactionmodel.Lint(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"uncovered-intent: intent OrderPizza has no handler",
+		"uncovered-intent: intent CancelOrder has no handler",
+		"uncovered-intent: intent Clova.GuideIntent has no handler",
+	}
+	if got := lintMessages(issues); !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues: %v; want %v", got, want)
+	}
+}
+
+func TestLintUtteranceCollisions(t *testing.T) {
+	m, err := interactionmodel.Load(filepath.Join("testdata", "lint", "collide.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := interactionmodel.Lint(m, filepath.Join("testdata", "lint", "handlers.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var collisions []*interactionmodel.Issue
+	for _, issue := range issues {
+		if issue.Kind == interactionmodel.IssueUtteranceCollision {
+			collisions = append(collisions, issue)
+		}
+	}
+	if len(collisions) != 1 {
+		t.Fatalf("collisions: %v", collisions)
+	}
+	want := "sample utterances collide: OrderPizza: {pizzaType}を注文して / ReorderPizza: {favorite} を注文して。"
+	if collisions[0].Message != want {
+		t.Errorf("message: %s; want %s", collisions[0].Message, want)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package interactionmodel loads the interaction model of a Clova extension,
+// that is its custom intents, their slots and sample utterances, and the
+// custom slot types.
+//
+// A model is read from JSON:
+//
+//	{
+//	  "intents": [
+//	    {
+//	      "name": "OrderPizza",
+//	      "slots": [{"name": "pizzaType", "type": "PIZZA_TYPE"}],
+//	      "sampleUtterances": ["{pizzaType}を注文して"]
+//	    }
+//	  ],
+//	  "slotTypes": [
+//	    {"name": "PIZZA_TYPE", "values": [{"value": "ペパロニ", "synonyms": ["ペペロニ"]}]}
+//	  ]
+//	}
+//
+// or from TSV, where the first column tells the kind of the record:
+//
+//	intent	OrderPizza
+//	slot	OrderPizza	pizzaType	PIZZA_TYPE
+//	utterance	OrderPizza	{pizzaType}を注文して
+//	value	PIZZA_TYPE	ペパロニ	ペペロニ
+//
+// Empty lines and lines starting with # are ignored in TSV.
+package interactionmodel
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Model type
+type Model struct {
+	Intents   []*Intent   `json:"intents"`
+	SlotTypes []*SlotType `json:"slotTypes,omitempty"`
+}
+
+// Intent type
+type Intent struct {
+	Name             string   `json:"name"`
+	Slots            []*Slot  `json:"slots,omitempty"`
+	SampleUtterances []string `json:"sampleUtterances,omitempty"`
+}
+
+// Slot type
+type Slot struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SlotType type
+type SlotType struct {
+	Name   string       `json:"name"`
+	Values []*SlotValue `json:"values"`
+}
+
+// SlotValue type
+type SlotValue struct {
+	Value    string   `json:"value"`
+	Synonyms []string `json:"synonyms,omitempty"`
+}
+
+// Intent method returns the intent with the name, or nil
+func (m *Model) Intent(name string) *Intent {
+	for _, intent := range m.Intents {
+		if intent.Name == name {
+			return intent
+		}
+	}
+	return nil
+}
+
+// SlotType method returns the custom slot type with the name, or nil
+func (m *Model) SlotType(name string) *SlotType {
+	for _, slotType := range m.SlotTypes {
+		if slotType.Name == name {
+			return slotType
+		}
+	}
+	return nil
+}
+
+// Slot method returns the slot with the name, or nil
+func (i *Intent) Slot(name string) *Slot {
+	for _, slot := range i.Slots {
+		if slot.Name == name {
+			return slot
+		}
+	}
+	return nil
+}
+
+// Load function reads the model file. Files with the .tsv extension are read
+// as TSV and the other files as JSON.
+func Load(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		return ParseTSV(f)
+	}
+	return ParseJSON(f)
+}
+
+// ParseJSON function
+func ParseJSON(r io.Reader) (*Model, error) {
+	m := &Model{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// tsvColumns is the minimum number of columns of each TSV record kind
+var tsvColumns = map[string]int{
+	"intent":    2,
+	"slot":      4,
+	"utterance": 3,
+	"slottype":  2,
+	"value":     3,
+}
+
+// ParseTSV function
+func ParseTSV(r io.Reader) (*Model, error) {
+	m := &Model{}
+	intent := func(name string) *Intent {
+		if i := m.Intent(name); i != nil {
+			return i
+		}
+		i := &Intent{Name: name}
+		m.Intents = append(m.Intents, i)
+		return i
+	}
+	slotType := func(name string) *SlotType {
+		if t := m.SlotType(name); t != nil {
+			return t
+		}
+		t := &SlotType{Name: name}
+		m.SlotTypes = append(m.SlotTypes, t)
+		return t
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		want, ok := tsvColumns[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown record %q", n, fields[0])
+		}
+		if len(fields) < want {
+			return nil, fmt.Errorf("line %d: %s record needs %d columns", n, fields[0], want)
+		}
+		switch fields[0] {
+		case "intent":
+			intent(fields[1])
+		case "slot":
+			i := intent(fields[1])
+			i.Slots = append(i.Slots, &Slot{Name: fields[2], Type: fields[3]})
+		case "utterance":
+			i := intent(fields[1])
+			i.SampleUtterances = append(i.SampleUtterances, fields[2])
+		case "slottype":
+			slotType(fields[1])
+		case "value":
+			t := slotType(fields[1])
+			value := &SlotValue{Value: fields[2]}
+			if len(fields) > 3 {
+				value.Synonyms = fields[3:]
+			}
+			t.Values = append(t.Values, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
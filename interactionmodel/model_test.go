@@ -0,0 +1,78 @@
+// Copyright 2018 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package interactionmodel_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/line/clova-cek-sdk-go/interactionmodel"
+)
+
+func TestLoad(t *testing.T) {
+	want := &interactionmodel.Model{
+		Intents: []*interactionmodel.Intent{
+			{
+				Name: "OrderPizza",
+				Slots: []*interactionmodel.Slot{
+					{Name: "pizzaType", Type: "PIZZA_TYPE"},
+					{Name: "when", Type: "CLOVA.TIME"},
+				},
+				SampleUtterances: []string{"{pizzaType}を注文して", "{when}に{pizzaType}を届けて"},
+			},
+			{
+				Name:             "CancelOrder",
+				SampleUtterances: []string{"注文をキャンセルして"},
+			},
+			{
+				Name: "Clova.GuideIntent",
+			},
+		},
+		SlotTypes: []*interactionmodel.SlotType{
+			{
+				Name: "PIZZA_TYPE",
+				Values: []*interactionmodel.SlotValue{
+					{Value: "ペパロニ", Synonyms: []string{"ペパロニピザ", "ペペロニ"}},
+					{Value: "マルゲリータ", Synonyms: []string{"マルゲリータピザ"}},
+					{Value: "seafood", Synonyms: []string{"シーフード"}},
+				},
+			},
+		},
+	}
+	for _, name := range []string{"pizza.json", "pizza.tsv"} {
+		m, err := interactionmodel.Load(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(m, want) {
+			t.Errorf("Model of %s: %+v; want %+v", name, m, want)
+		}
+	}
+}
+
+func TestParseTSVError(t *testing.T) {
+	testCases := []string{
+		"intent",
+		"slot\tOrderPizza\tpizzaType",
+		"unknown\tOrderPizza",
+	}
+	for i, testCase := range testCases {
+		if _, err := interactionmodel.ParseTSV(strings.NewReader(testCase)); err == nil {
+			t.Errorf("ParseTSV %d: no error", i)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package pizza
+
+import (
+	"context"
+
+	"github.com/line/clova-cek-sdk-go/cek"
+)
+
+const IntentOrderPizza = "OrderPizza"
+
+func newMux() *cek.ServeMux {
+	mux := cek.NewServeMux()
+	mux.HandleIntent(IntentOrderPizza, cek.HandlerFunc(func(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+		intent := message.Request.(*cek.IntentRequest).Intent
+		_ = intent.Slots["pizzaType"]
+		_ = intent.Slots["size"]
+		return cek.NewResponseBuilder().Build(), nil
+	}))
+	mux.HandleIntent(cek.BuiltinIntentGuide, cek.GuideIntentHandler(nil))
+	mux.HandleIntent(cek.BuiltinIntentCancel, cek.CancelIntentHandler(nil))
+	mux.HandleIntent("TrackOrder", cek.HandlerFunc(handleTrackOrder))
+	return mux
+}
+
+func handleTrackOrder(ctx context.Context, message *cek.RequestMessage) (*cek.ResponseMessage, error) {
+	intent := message.Request.(*cek.IntentRequest).Intent
+	_ = intent.Slots["when"]
+	_ = intent.Slots["orderNumber"]
+	return cek.NewResponseBuilder().Build(), nil
+}
+
+func handleLegacy(message *cek.RequestMessage) {
+	switch request := message.Request.(type) {
+	case *cek.IntentRequest:
+		switch request.Intent.Name {
+		case "CancelOrder":
+			_ = request.Intent.Slots["pizzaType"]
+		}
+	}
+}